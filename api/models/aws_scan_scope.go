@@ -0,0 +1,83 @@
+// Copyright © 2022 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import "encoding/json"
+
+// AwsScanScope describes which AWS regions, VPCs and instances a scan scope
+// should cover.
+type AwsScanScope struct {
+	AllRegions                 *bool        `json:"allRegions,omitempty"`
+	Regions                    *[]AwsRegion `json:"regions,omitempty"`
+	ShouldScanStoppedInstances *bool        `json:"shouldScanStoppedInstances,omitempty"`
+	InstanceTagSelector        *[]Tag       `json:"instanceTagSelector,omitempty"`
+	InstanceTagExclusion       *[]Tag       `json:"instanceTagExclusion,omitempty"`
+
+	// ExcludeRegions lists region names (supporting glob patterns such as
+	// "us-gov-*" or "cn-*") to skip even when AllRegions is set.
+	ExcludeRegions *[]string `json:"excludeRegions,omitempty"`
+	// ExcludeVPCs lists VPC IDs to skip across all scanned regions.
+	ExcludeVPCs *[]string `json:"excludeVPCs,omitempty"`
+	// ExcludeInstanceIDs lists instance IDs to skip regardless of which
+	// region or VPC they're discovered in.
+	ExcludeInstanceIDs *[]string `json:"excludeInstanceIDs,omitempty"`
+}
+
+type AwsRegion struct {
+	Name string    `json:"name"`
+	Vpcs *[]AwsVPC `json:"vpcs,omitempty"`
+}
+
+type AwsVPC struct {
+	Id             string              `json:"id"`
+	SecurityGroups *[]AwsSecurityGroup `json:"securityGroups,omitempty"`
+}
+
+type AwsSecurityGroup struct {
+	Id string `json:"id"`
+}
+
+type Tag struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// ScanScopeType is the discriminated-union wrapper returned by the API for a
+// scan scope; AsAwsScanScope unwraps the AWS-specific variant.
+type ScanScopeType struct {
+	union json.RawMessage
+}
+
+func (t *ScanScopeType) AsAwsScanScope() (AwsScanScope, error) {
+	var body AwsScanScope
+	err := json.Unmarshal(t.union, &body)
+	return body, err
+}
+
+// DeepCopy returns a copy of t with its own copy of the underlying
+// json.RawMessage, so callers that deep-copy a value containing a
+// ScanScopeType (such as the ScanEstimation CRD's generated DeepCopyInto)
+// don't end up sharing the original's backing array.
+func (t *ScanScopeType) DeepCopy() *ScanScopeType {
+	if t == nil {
+		return nil
+	}
+	out := new(ScanScopeType)
+	if t.union != nil {
+		out.union = append(json.RawMessage(nil), t.union...)
+	}
+	return out
+}
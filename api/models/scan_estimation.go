@@ -0,0 +1,83 @@
+// Copyright © 2023 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+type ScanEstimationID = string
+
+// ScanEstimation projects the cost and duration of scanning the assets
+// matched by Scope, without actually running a scan.
+type ScanEstimation struct {
+	Id       *string        `json:"id,omitempty"`
+	Revision *int           `json:"revision,omitempty"`
+	Scope    *ScanScopeType `json:"scope,omitempty"`
+
+	// Estimation holds the cost/duration projection computed from Scope.
+	// It's populated by the server and should not be set by clients.
+	Estimation *Estimation `json:"estimation,omitempty"`
+}
+
+// Estimation is the server-computed cost/duration projection for a
+// ScanEstimation.
+type Estimation struct {
+	Cost *CostBreakdown `json:"cost,omitempty"`
+}
+
+// CostBreakdown projects the cost of running a scan matching a
+// ScanEstimation's scope, in the units each AWS pricing dimension bills in.
+type CostBreakdown struct {
+	ScannerVMHours   float32 `json:"scannerVmHours"`
+	SnapshotGBMonths float32 `json:"snapshotGbMonths"`
+	DataTransferGB   float32 `json:"dataTransferGb"`
+
+	// TotalEstimatedCostUSD is ScannerVMHours/SnapshotGBMonths/DataTransferGB
+	// multiplied by the AWS Pricing API rates in effect when the estimate
+	// was computed.
+	TotalEstimatedCostUSD float32 `json:"totalEstimatedCostUsd"`
+}
+
+type ScanEstimations struct {
+	Items *[]ScanEstimation `json:"items,omitempty"`
+	Count *int              `json:"count,omitempty"`
+}
+
+type GetScanEstimationsParams struct {
+	Filter  *string `json:"$filter,omitempty"`
+	Select  *string `json:"$select,omitempty"`
+	Expand  *string `json:"$expand,omitempty"`
+	OrderBy *string `json:"$orderby,omitempty"`
+	Top     *int    `json:"$top,omitempty"`
+	Skip    *int    `json:"$skip,omitempty"`
+	Count   *bool   `json:"$count,omitempty"`
+}
+
+type GetScanEstimationsScanEstimationIDParams struct {
+	Select *string `json:"$select,omitempty"`
+	Expand *string `json:"$expand,omitempty"`
+}
+
+type PutScanEstimationsScanEstimationIDParams struct {
+	IfMatch     *string `json:"-"`
+	IfNoneMatch *string `json:"-"`
+}
+
+type PatchScanEstimationsScanEstimationIDParams struct {
+	IfMatch     *string `json:"-"`
+	IfNoneMatch *string `json:"-"`
+}
+
+type Success struct {
+	Message *string `json:"message,omitempty"`
+}
@@ -0,0 +1,115 @@
+// Copyright © 2023 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openclarity/vmclarity/api/models"
+)
+
+// DeliveryMode selects how a ScanEstimation's eventual scan would reach its
+// scanner instances, mirroring the choice operators already make for
+// Service: a cluster-routed Ingress, or direct NodePort access.
+type DeliveryMode string
+
+const (
+	// DeliveryModeIngress routes scan delivery through a cluster Ingress.
+	DeliveryModeIngress DeliveryMode = "Ingress"
+	// DeliveryModeNodePort exposes scan delivery directly on each node.
+	DeliveryModeNodePort DeliveryMode = "NodePort"
+)
+
+// SupportedDeliveryModes lists the DeliveryMode values the reconciler
+// accepts; any other value fails validation.
+var SupportedDeliveryModes = []DeliveryMode{DeliveryModeIngress, DeliveryModeNodePort}
+
+// Phase reports where a ScanEstimation is in its reconciliation lifecycle.
+type Phase string
+
+const (
+	PhasePending  Phase = "Pending"
+	PhaseReady    Phase = "Ready"
+	PhaseFailed   Phase = "Failed"
+	PhaseDeleting Phase = "Deleting"
+)
+
+// ScanEstimationSpec is the desired state of a ScanEstimation CR: the scope
+// to estimate, and how its eventual scan would be delivered.
+type ScanEstimationSpec struct {
+	// Scope selects the assets the cost/duration projection covers.
+	Scope *models.ScanScopeType `json:"scope,omitempty"`
+
+	// DeliveryMode selects how the eventual scan reaches its scanner
+	// instances. Defaults to DeliveryModeIngress.
+	// +kubebuilder:validation:Enum=Ingress;NodePort
+	// +kubebuilder:default=Ingress
+	DeliveryMode DeliveryMode `json:"deliveryMode,omitempty"`
+}
+
+// ScanEstimationStatus is the observed state of a ScanEstimation CR, kept in
+// sync with the underlying ScanEstimation DB record by the reconciler.
+type ScanEstimationStatus struct {
+	// Phase summarizes reconciliation progress.
+	Phase Phase `json:"phase,omitempty"`
+
+	// ScanEstimationID is the ID of the DB-backed ScanEstimation this CR
+	// owns, once created.
+	ScanEstimationID string `json:"scanEstimationID,omitempty"`
+
+	// EstimatedCostUSD mirrors CostBreakdown.TotalEstimatedCostUSD once
+	// the estimator has run.
+	EstimatedCostUSD *float32 `json:"estimatedCostUSD,omitempty"`
+
+	// EstimatedDuration mirrors the assumed scan duration the cost
+	// projection was computed against.
+	EstimatedDuration *metav1.Duration `json:"estimatedDuration,omitempty"`
+
+	// LastError holds the most recent reconciliation error, if Phase is
+	// PhaseFailed.
+	LastError string `json:"lastError,omitempty"`
+
+	// ObservedGeneration is the Spec generation the status was last
+	// computed from, so clients can tell a stale status from a current one.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Cost (USD)",type=string,JSONPath=".status.estimatedCostUSD"
+
+// ScanEstimation is the Schema for the scanestimations API.
+type ScanEstimation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ScanEstimationSpec   `json:"spec,omitempty"`
+	Status ScanEstimationStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ScanEstimationList contains a list of ScanEstimation.
+type ScanEstimationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ScanEstimation `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ScanEstimation{}, &ScanEstimationList{})
+}
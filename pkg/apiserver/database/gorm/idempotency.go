@@ -0,0 +1,121 @@
+// Copyright © 2023 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/openclarity/vmclarity/pkg/apiserver/database/types"
+)
+
+// idempotencyKeyTTL bounds how long an Idempotency-Key is remembered; once
+// it expires, a repeat POST with the same key is treated as a new request
+// rather than a replay.
+const idempotencyKeyTTL = 24 * time.Hour
+
+// IdempotencyKey is the persisted outcome of a POST made with an
+// Idempotency-Key header. A row with an empty ScanEstimationID is a live
+// reservation (Reserve has been called but not yet Finalize/Release); this
+// API has no authenticated principal to additionally scope the key to, so
+// clients are expected to generate a key unique to the logical request
+// (e.g. a UUID) rather than reuse a short value across unrelated requests.
+type IdempotencyKey struct {
+	Key              string `gorm:"primarykey"`
+	ScanEstimationID string
+	ResponseHash     string
+	CreatedAt        time.Time
+}
+
+type IdempotencyKeysTableHandler struct {
+	DB *gorm.DB
+}
+
+func (db *Handler) IdempotencyKeysTable() types.IdempotencyKeysTable {
+	return &IdempotencyKeysTableHandler{
+		DB: db.DB,
+	}
+}
+
+func (s *IdempotencyKeysTableHandler) Get(ctx context.Context, key string) (*types.IdempotencyRecord, error) {
+	var row IdempotencyKey
+	err := s.DB.WithContext(ctx).
+		Where("key = ? AND scan_estimation_id != '' AND created_at > ?", key, time.Now().Add(-idempotencyKeyTTL)).
+		First(&row).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to look up idempotency key: %w", err)
+	}
+
+	return &types.IdempotencyRecord{
+		ScanEstimationID: row.ScanEstimationID,
+		ResponseHash:     row.ResponseHash,
+	}, nil
+}
+
+func (s *IdempotencyKeysTableHandler) Reserve(ctx context.Context, key string) error {
+	now := time.Now()
+
+	res := s.DB.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&IdempotencyKey{Key: key, CreatedAt: now})
+	if res.Error != nil {
+		return fmt.Errorf("failed to reserve idempotency key: %w", res.Error)
+	}
+	if res.RowsAffected == 1 {
+		return nil
+	}
+
+	// key already exists - only take it over if the existing row has
+	// expired, so a request that crashed between Reserve and
+	// Finalize/Release doesn't wedge the key forever.
+	res = s.DB.WithContext(ctx).Model(&IdempotencyKey{}).
+		Where("key = ? AND created_at <= ?", key, now.Add(-idempotencyKeyTTL)).
+		Updates(map[string]any{"scan_estimation_id": "", "response_hash": "", "created_at": now})
+	if res.Error != nil {
+		return fmt.Errorf("failed to reclaim expired idempotency key: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return types.ErrIdempotencyKeyConflict
+	}
+
+	return nil
+}
+
+func (s *IdempotencyKeysTableHandler) Finalize(ctx context.Context, key string, record types.IdempotencyRecord) error {
+	res := s.DB.WithContext(ctx).Model(&IdempotencyKey{}).Where("key = ?", key).
+		Updates(map[string]any{"scan_estimation_id": record.ScanEstimationID, "response_hash": record.ResponseHash})
+	if res.Error != nil {
+		return fmt.Errorf("failed to finalize idempotency key: %w", res.Error)
+	}
+
+	return nil
+}
+
+func (s *IdempotencyKeysTableHandler) Release(ctx context.Context, key string) error {
+	if err := s.DB.WithContext(ctx).Where("key = ?", key).Delete(&IdempotencyKey{}).Error; err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+
+	return nil
+}
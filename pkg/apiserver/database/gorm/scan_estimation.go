@@ -16,6 +16,7 @@
 package gorm
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -26,30 +27,43 @@ import (
 	"github.com/openclarity/vmclarity/api/models"
 	"github.com/openclarity/vmclarity/pkg/apiserver/common"
 	"github.com/openclarity/vmclarity/pkg/apiserver/database/types"
+	"github.com/openclarity/vmclarity/pkg/apiserver/estimation"
 	"github.com/openclarity/vmclarity/pkg/shared/utils"
 )
 
 const (
 	scanEstimationSchemaName = "ScanEstimation"
+
+	// estimatorScannerInstanceType is the instance type costed when
+	// projecting a ScanEstimation; it should track the instance type
+	// RunScanningJob actually launches.
+	estimatorScannerInstanceType = "t2.large"
 )
 
 type ScanEstimation struct {
 	ODataObject
 }
 
+// ScanEstimationsTableHandler computes/persists ScanEstimations. Estimator is
+// wired in via Handler.Estimator; if it's nil, CreateScanEstimation persists
+// the caller-provided CostBreakdown as-is and RefreshScanEstimation fails.
 type ScanEstimationsTableHandler struct {
-	DB *gorm.DB
+	DB        *gorm.DB
+	Estimator *estimation.Estimator
 }
 
 func (db *Handler) ScanEstimationsTable() types.ScanEstimationsTable {
 	return &ScanEstimationsTableHandler{
-		DB: db.DB,
+		DB:        db.DB,
+		Estimator: db.Estimator,
 	}
 }
 
-func (s *ScanEstimationsTableHandler) GetScanEstimations(params models.GetScanEstimationsParams) (models.ScanEstimations, error) {
+func (s *ScanEstimationsTableHandler) GetScanEstimations(ctx context.Context, params models.GetScanEstimationsParams) (models.ScanEstimations, error) {
+	db := s.DB.WithContext(ctx)
+
 	var scanEstimations []ScanEstimation
-	err := ODataQuery(s.DB, scanEstimationSchemaName, params.Filter, params.Select, params.Expand, params.OrderBy, params.Top, params.Skip, true, &scanEstimations)
+	err := ODataQuery(db, scanEstimationSchemaName, params.Filter, params.Select, params.Expand, params.OrderBy, params.Top, params.Skip, true, &scanEstimations)
 	if err != nil {
 		return models.ScanEstimations{}, err
 	}
@@ -67,7 +81,7 @@ func (s *ScanEstimationsTableHandler) GetScanEstimations(params models.GetScanEs
 	output := models.ScanEstimations{Items: &items}
 
 	if params.Count != nil && *params.Count {
-		count, err := ODataCount(s.DB, scanEstimationSchemaName, params.Filter)
+		count, err := ODataCount(db, scanEstimationSchemaName, params.Filter)
 		if err != nil {
 			return models.ScanEstimations{}, fmt.Errorf("failed to count records: %w", err)
 		}
@@ -77,10 +91,10 @@ func (s *ScanEstimationsTableHandler) GetScanEstimations(params models.GetScanEs
 	return output, nil
 }
 
-func (s *ScanEstimationsTableHandler) GetScanEstimation(scanEstimationID models.ScanEstimationID, params models.GetScanEstimationsScanEstimationIDParams) (models.ScanEstimation, error) {
+func (s *ScanEstimationsTableHandler) GetScanEstimation(ctx context.Context, scanEstimationID models.ScanEstimationID, params models.GetScanEstimationsScanEstimationIDParams) (models.ScanEstimation, error) {
 	var dbScanEstimation ScanEstimation
 	filter := fmt.Sprintf("id eq '%s'", scanEstimationID)
-	err := ODataQuery(s.DB, scanEstimationSchemaName, &filter, params.Select, params.Expand, nil, nil, nil, false, &dbScanEstimation)
+	err := ODataQuery(s.DB.WithContext(ctx), scanEstimationSchemaName, &filter, params.Select, params.Expand, nil, nil, nil, false, &dbScanEstimation)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return models.ScanEstimation{}, types.ErrNotFound
@@ -97,7 +111,7 @@ func (s *ScanEstimationsTableHandler) GetScanEstimation(scanEstimationID models.
 	return apiScanEstimation, nil
 }
 
-func (s *ScanEstimationsTableHandler) CreateScanEstimation(scanEstimation models.ScanEstimation) (models.ScanEstimation, error) {
+func (s *ScanEstimationsTableHandler) CreateScanEstimation(ctx context.Context, scanEstimation models.ScanEstimation) (models.ScanEstimation, error) {
 	// Check the user didn't provide an ID
 	if scanEstimation.Id != nil {
 		return models.ScanEstimation{}, &common.BadRequestError{
@@ -111,6 +125,14 @@ func (s *ScanEstimationsTableHandler) CreateScanEstimation(scanEstimation models
 	// Initialise revision
 	scanEstimation.Revision = utils.PointerTo(1)
 
+	if s.Estimator != nil {
+		cost, err := s.Estimator.Estimate(ctx, &scanEstimation, estimatorScannerInstanceType)
+		if err != nil {
+			return models.ScanEstimation{}, fmt.Errorf("failed to estimate scan cost: %w", err)
+		}
+		scanEstimation.Estimation = &models.Estimation{Cost: &cost}
+	}
+
 	marshaled, err := json.Marshal(scanEstimation)
 	if err != nil {
 		return models.ScanEstimation{}, fmt.Errorf("failed to convert API model to DB model: %w", err)
@@ -119,7 +141,7 @@ func (s *ScanEstimationsTableHandler) CreateScanEstimation(scanEstimation models
 	newScanEstimation := ScanEstimation{}
 	newScanEstimation.Data = marshaled
 
-	if err = s.DB.Create(&newScanEstimation).Error; err != nil {
+	if err = s.DB.WithContext(ctx).Create(&newScanEstimation).Error; err != nil {
 		return models.ScanEstimation{}, fmt.Errorf("failed to create scan estimation in db: %w", err)
 	}
 
@@ -133,15 +155,17 @@ func (s *ScanEstimationsTableHandler) CreateScanEstimation(scanEstimation models
 }
 
 // nolint:cyclop
-func (s *ScanEstimationsTableHandler) SaveScanEstimation(scanEstimation models.ScanEstimation, params models.PutScanEstimationsScanEstimationIDParams) (models.ScanEstimation, error) {
+func (s *ScanEstimationsTableHandler) SaveScanEstimation(ctx context.Context, scanEstimation models.ScanEstimation, params models.PutScanEstimationsScanEstimationIDParams) (models.ScanEstimation, error) {
 	if scanEstimation.Id == nil || *scanEstimation.Id == "" {
 		return models.ScanEstimation{}, &common.BadRequestError{
 			Reason: "id is required to save scan estimation",
 		}
 	}
 
+	db := s.DB.WithContext(ctx)
+
 	var dbObj ScanEstimation
-	if err := getExistingObjByID(s.DB, scanEstimationSchemaName, *scanEstimation.Id, &dbObj); err != nil {
+	if err := getExistingObjByID(db, scanEstimationSchemaName, *scanEstimation.Id, &dbObj); err != nil {
 		return models.ScanEstimation{}, fmt.Errorf("failed to get scan estimation from db: %w", err)
 	}
 
@@ -153,6 +177,9 @@ func (s *ScanEstimationsTableHandler) SaveScanEstimation(scanEstimation models.S
 	if err := checkRevisionEtag(params.IfMatch, dbScanEstimation.Revision); err != nil {
 		return models.ScanEstimation{}, err
 	}
+	if err := checkIfNoneMatch(params.IfNoneMatch, dbScanEstimation.Revision); err != nil {
+		return models.ScanEstimation{}, err
+	}
 
 	scanEstimation.Revision = bumpRevision(dbScanEstimation.Revision)
 
@@ -163,7 +190,7 @@ func (s *ScanEstimationsTableHandler) SaveScanEstimation(scanEstimation models.S
 
 	dbObj.Data = marshaled
 
-	if err = s.DB.Save(&dbObj).Error; err != nil {
+	if err = db.Save(&dbObj).Error; err != nil {
 		return models.ScanEstimation{}, fmt.Errorf("failed to save scan estimation in db: %w", err)
 	}
 
@@ -176,15 +203,17 @@ func (s *ScanEstimationsTableHandler) SaveScanEstimation(scanEstimation models.S
 }
 
 // nolint:cyclop
-func (s *ScanEstimationsTableHandler) UpdateScanEstimation(scanEstimation models.ScanEstimation, params models.PatchScanEstimationsScanEstimationIDParams) (models.ScanEstimation, error) {
+func (s *ScanEstimationsTableHandler) UpdateScanEstimation(ctx context.Context, scanEstimation models.ScanEstimation, params models.PatchScanEstimationsScanEstimationIDParams) (models.ScanEstimation, error) {
 	if scanEstimation.Id == nil || *scanEstimation.Id == "" {
 		return models.ScanEstimation{}, &common.BadRequestError{
 			Reason: "id is required to update scan estimation",
 		}
 	}
 
+	db := s.DB.WithContext(ctx)
+
 	var dbObj ScanEstimation
-	if err := getExistingObjByID(s.DB, scanEstimationSchemaName, *scanEstimation.Id, &dbObj); err != nil {
+	if err := getExistingObjByID(db, scanEstimationSchemaName, *scanEstimation.Id, &dbObj); err != nil {
 		return models.ScanEstimation{}, err
 	}
 
@@ -196,6 +225,9 @@ func (s *ScanEstimationsTableHandler) UpdateScanEstimation(scanEstimation models
 	if err := checkRevisionEtag(params.IfMatch, dbScanEstimation.Revision); err != nil {
 		return models.ScanEstimation{}, err
 	}
+	if err := checkIfNoneMatch(params.IfNoneMatch, dbScanEstimation.Revision); err != nil {
+		return models.ScanEstimation{}, err
+	}
 
 	scanEstimation.Revision = bumpRevision(dbScanEstimation.Revision)
 
@@ -211,17 +243,88 @@ func (s *ScanEstimationsTableHandler) UpdateScanEstimation(scanEstimation models
 		return models.ScanEstimation{}, fmt.Errorf("failed to convert DB model to API model: %w", err)
 	}
 
-	if err := s.DB.Save(&dbObj).Error; err != nil {
+	if err := db.Save(&dbObj).Error; err != nil {
 		return models.ScanEstimation{}, fmt.Errorf("failed to save scan estimation in db: %w", err)
 	}
 
 	return ret, nil
 }
 
-func (s *ScanEstimationsTableHandler) DeleteScanEstimation(scanEstimationID models.ScanEstimationID) error {
-	if err := deleteObjByID(s.DB, scanEstimationID, &ScanEstimation{}); err != nil {
+// RefreshScanEstimation re-runs the cost estimator against the stored
+// ScanEstimation's existing scope and persists the updated CostBreakdown,
+// without otherwise changing the resource.
+func (s *ScanEstimationsTableHandler) RefreshScanEstimation(ctx context.Context, scanEstimationID models.ScanEstimationID) (models.ScanEstimation, error) {
+	if s.Estimator == nil {
+		return models.ScanEstimation{}, fmt.Errorf("no cost estimator configured")
+	}
+
+	db := s.DB.WithContext(ctx)
+
+	var dbObj ScanEstimation
+	if err := getExistingObjByID(db, scanEstimationSchemaName, scanEstimationID, &dbObj); err != nil {
+		return models.ScanEstimation{}, fmt.Errorf("failed to get scan estimation from db: %w", err)
+	}
+
+	var scanEstimation models.ScanEstimation
+	if err := json.Unmarshal(dbObj.Data, &scanEstimation); err != nil {
+		return models.ScanEstimation{}, fmt.Errorf("failed to convert DB object to API model: %w", err)
+	}
+
+	cost, err := s.Estimator.Estimate(ctx, &scanEstimation, estimatorScannerInstanceType)
+	if err != nil {
+		return models.ScanEstimation{}, fmt.Errorf("failed to estimate scan cost: %w", err)
+	}
+	scanEstimation.Estimation = &models.Estimation{Cost: &cost}
+	scanEstimation.Revision = bumpRevision(scanEstimation.Revision)
+
+	marshaled, err := json.Marshal(scanEstimation)
+	if err != nil {
+		return models.ScanEstimation{}, fmt.Errorf("failed to convert API model to DB model: %w", err)
+	}
+	dbObj.Data = marshaled
+
+	if err := db.Save(&dbObj).Error; err != nil {
+		return models.ScanEstimation{}, fmt.Errorf("failed to save scan estimation in db: %w", err)
+	}
+
+	return scanEstimation, nil
+}
+
+func (s *ScanEstimationsTableHandler) DeleteScanEstimation(ctx context.Context, scanEstimationID models.ScanEstimationID) error {
+	if err := deleteObjByID(s.DB.WithContext(ctx), scanEstimationID, &ScanEstimation{}); err != nil {
 		return fmt.Errorf("failed to delete scan estimation: %w", err)
 	}
 
 	return nil
 }
+
+// checkIfNoneMatch enforces an RFC 7232 If-None-Match precondition: it
+// fails the request with a PreconditionFailedError if ifNoneMatch matches
+// revision (or is "*", which matches any existing resource), mirroring the
+// If-Match handling in checkRevisionEtag.
+func checkIfNoneMatch(ifNoneMatch *string, revision *int) error {
+	if ifNoneMatch == nil {
+		return nil
+	}
+
+	if matchesRevisionEtag(*ifNoneMatch, revision) {
+		return &types.PreconditionFailedError{
+			Reason: fmt.Sprintf("If-None-Match %q matches the current revision", *ifNoneMatch),
+		}
+	}
+
+	return nil
+}
+
+// matchesRevisionEtag reports whether etag - an RFC 7232 If-Match/
+// If-None-Match value - matches revision.
+func matchesRevisionEtag(etag string, revision *int) bool {
+	if etag == "*" {
+		return revision != nil
+	}
+	if revision == nil {
+		return false
+	}
+
+	return etag == fmt.Sprintf(`"%d"`, *revision)
+}
@@ -0,0 +1,95 @@
+// Copyright © 2023 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gorm
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/openclarity/vmclarity/pkg/apiserver/webhooks"
+)
+
+// WebhookSubscriber is the persisted record of a registered webhook
+// endpoint. Unlike the OData-backed resources in this package, subscribers
+// aren't exposed over the REST API yet, so this is a plain gorm model
+// rather than an ODataObject.
+type WebhookSubscriber struct {
+	ID     string `gorm:"primarykey"`
+	URL    string
+	Secret string
+}
+
+// WebhookDeliveryAttempt records the outcome of a single attempt to deliver
+// an event to a subscriber, for auditing and troubleshooting failed
+// deliveries.
+type WebhookDeliveryAttempt struct {
+	gorm.Model
+	EventID      string
+	SubscriberID string
+	Attempt      int
+	StatusCode   int
+	Error        string
+}
+
+// WebhookSubscribersTableHandler implements webhooks.SubscriberStore and
+// webhooks.DeliveryRecorder on top of the gorm DB.
+type WebhookSubscribersTableHandler struct {
+	DB *gorm.DB
+}
+
+func (db *Handler) WebhookSubscribersTable() *WebhookSubscribersTableHandler {
+	return &WebhookSubscribersTableHandler{
+		DB: db.DB,
+	}
+}
+
+func (s *WebhookSubscribersTableHandler) ListSubscribers(ctx context.Context) ([]webhooks.Subscriber, error) {
+	var rows []WebhookSubscriber
+	if err := s.DB.WithContext(ctx).Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to list webhook subscribers: %w", err)
+	}
+
+	subscribers := make([]webhooks.Subscriber, len(rows))
+	for i, row := range rows {
+		subscribers[i] = webhooks.Subscriber{
+			ID:     row.ID,
+			URL:    row.URL,
+			Secret: row.Secret,
+		}
+	}
+
+	return subscribers, nil
+}
+
+func (s *WebhookSubscribersTableHandler) RecordDeliveryAttempt(ctx context.Context, eventID, subscriberID string, attempt int, statusCode int, deliveryErr error) error {
+	record := WebhookDeliveryAttempt{
+		EventID:      eventID,
+		SubscriberID: subscriberID,
+		Attempt:      attempt,
+		StatusCode:   statusCode,
+	}
+	if deliveryErr != nil {
+		record.Error = deliveryErr.Error()
+	}
+
+	if err := s.DB.WithContext(ctx).Create(&record).Error; err != nil {
+		return fmt.Errorf("failed to record webhook delivery attempt: %w", err)
+	}
+
+	return nil
+}
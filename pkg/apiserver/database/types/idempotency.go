@@ -0,0 +1,59 @@
+// Copyright © 2023 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrIdempotencyKeyConflict is returned when an Idempotency-Key is reused
+// for a request whose response doesn't match what was recorded for the
+// original request, e.g. because the originally created resource was since
+// deleted or mutated out from under the key. IdempotencyKeysTable.Reserve
+// also returns it when the key is held by another unexpired reservation.
+var ErrIdempotencyKeyConflict = errors.New("idempotency key reused but the original response could not be reproduced")
+
+// IdempotencyRecord is the stored outcome of a POST made with an
+// Idempotency-Key, so a retried POST (e.g. replayed by a proxy) can be
+// answered with the original result instead of creating a duplicate.
+type IdempotencyRecord struct {
+	ScanEstimationID string
+	ResponseHash     string
+}
+
+// IdempotencyKeysTable is the persistence interface for Idempotency-Key
+// deduplication records. Reserve/Finalize/Release exist (rather than a
+// single Put) so a caller can atomically claim a key before doing the work
+// it guards: that's what stops two concurrent requests for the same key
+// from both creating a resource.
+type IdempotencyKeysTable interface {
+	// Get returns the finalized record stored for key, or (nil, nil) if
+	// no unexpired, finalized record exists.
+	Get(ctx context.Context, key string) (*IdempotencyRecord, error)
+	// Reserve atomically claims key for the caller. It returns
+	// ErrIdempotencyKeyConflict if key is already claimed by another,
+	// unexpired reservation. The caller must follow a successful Reserve
+	// with Finalize (on success) or Release (on failure).
+	Reserve(ctx context.Context, key string) error
+	// Finalize records the outcome of the request that holds key's
+	// reservation.
+	Finalize(ctx context.Context, key string, record IdempotencyRecord) error
+	// Release discards a reservation that was never finalized, so the
+	// key is immediately available to reclaim rather than waiting out
+	// idempotencyKeyTTL.
+	Release(ctx context.Context, key string) error
+}
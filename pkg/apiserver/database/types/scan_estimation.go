@@ -0,0 +1,53 @@
+// Copyright © 2023 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/openclarity/vmclarity/api/models"
+)
+
+// ErrNotFound is returned by table handlers when the requested resource
+// doesn't exist.
+var ErrNotFound = errors.New("not found")
+
+// PreconditionFailedError is returned by table handlers when a conditional
+// update (If-Match/If-None-Match) doesn't match the resource's current
+// revision.
+type PreconditionFailedError struct {
+	Reason string
+}
+
+func (e *PreconditionFailedError) Error() string {
+	return fmt.Sprintf("precondition failed: %s", e.Reason)
+}
+
+// ScanEstimationsTable is the persistence interface for ScanEstimations.
+// Every method takes a context so callers can bound the operation with a
+// deadline; implementations must honor its cancellation rather than running
+// a query to completion regardless.
+type ScanEstimationsTable interface {
+	GetScanEstimations(ctx context.Context, params models.GetScanEstimationsParams) (models.ScanEstimations, error)
+	GetScanEstimation(ctx context.Context, scanEstimationID models.ScanEstimationID, params models.GetScanEstimationsScanEstimationIDParams) (models.ScanEstimation, error)
+	CreateScanEstimation(ctx context.Context, scanEstimation models.ScanEstimation) (models.ScanEstimation, error)
+	SaveScanEstimation(ctx context.Context, scanEstimation models.ScanEstimation, params models.PutScanEstimationsScanEstimationIDParams) (models.ScanEstimation, error)
+	UpdateScanEstimation(ctx context.Context, scanEstimation models.ScanEstimation, params models.PatchScanEstimationsScanEstimationIDParams) (models.ScanEstimation, error)
+	RefreshScanEstimation(ctx context.Context, scanEstimationID models.ScanEstimationID) (models.ScanEstimation, error)
+	DeleteScanEstimation(ctx context.Context, scanEstimationID models.ScanEstimationID) error
+}
@@ -0,0 +1,170 @@
+// Copyright © 2023 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package estimation turns a ScanEstimation's scope into a projected
+// CostBreakdown by discovering the assets it targets and pricing them
+// against the AWS Pricing API, so ScanEstimation is a real forecasting
+// feature instead of a passive record of whatever JSON the caller sent.
+package estimation
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/pricing"
+
+	"github.com/openclarity/vmclarity/api/models"
+	"github.com/openclarity/vmclarity/runtime_scan/pkg/provider"
+	"github.com/openclarity/vmclarity/runtime_scan/pkg/types"
+)
+
+// AssumedScanDurationHours is the scanner runtime assumed per target until
+// we have real historical scan-duration data to estimate from.
+// TODO base this on the asset's volume size / previous scan durations.
+const AssumedScanDurationHours = 1.0
+
+// priceCacheTTL bounds how long a (region, resourceType, variant) price is
+// reused before going back to the Pricing API.
+const priceCacheTTL = 24 * time.Hour
+
+// PricingClient is the subset of the Pricing SDK client the estimator needs,
+// so tests can provide a fake.
+type PricingClient interface {
+	GetProducts(ctx context.Context, params *pricing.GetProductsInput, optFns ...func(*pricing.Options)) (*pricing.GetProductsOutput, error)
+}
+
+type priceCacheKey struct {
+	region       string
+	resourceType string // "ec2" or "ebs"
+	variant      string // instance type, or EBS volume type
+}
+
+type priceCacheEntry struct {
+	pricePerUnit float64
+	expiresAt    time.Time
+}
+
+type priceCache struct {
+	mu      sync.Mutex
+	entries map[priceCacheKey]priceCacheEntry
+}
+
+func newPriceCache() *priceCache {
+	return &priceCache{entries: make(map[priceCacheKey]priceCacheEntry)}
+}
+
+func (c *priceCache) get(key priceCacheKey) (float64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return 0, false
+	}
+	return entry.pricePerUnit, true
+}
+
+func (c *priceCache) set(key priceCacheKey, pricePerUnit float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = priceCacheEntry{pricePerUnit: pricePerUnit, expiresAt: time.Now().Add(priceCacheTTL)}
+}
+
+// Estimator computes a models.CostBreakdown for a ScanEstimation's scope.
+type Estimator struct {
+	provider      provider.Provider
+	pricingClient PricingClient
+	prices        *priceCache
+}
+
+func NewEstimator(p provider.Provider, pricingClient PricingClient) *Estimator {
+	return &Estimator{
+		provider:      p,
+		pricingClient: pricingClient,
+		prices:        newPriceCache(),
+	}
+}
+
+// Estimate discovers the assets matched by scanEstimation's scope and
+// returns the projected CostBreakdown for scanning them with instanceType
+// scanner instances.
+func (e *Estimator) Estimate(ctx context.Context, scanEstimation *models.ScanEstimation, instanceType string) (models.CostBreakdown, error) {
+	if scanEstimation.Scope == nil {
+		return models.CostBreakdown{}, fmt.Errorf("scan estimation has no scope to estimate")
+	}
+
+	instances, err := e.provider.Discover(ctx, scanEstimation.Scope)
+	if err != nil {
+		return models.CostBreakdown{}, fmt.Errorf("failed to discover assets for scan estimation: %w", err)
+	}
+
+	var breakdown models.CostBreakdown
+	for _, instance := range instances {
+		instanceBreakdown, err := e.estimateInstance(ctx, instance, instanceType)
+		if err != nil {
+			return models.CostBreakdown{}, fmt.Errorf("failed to estimate cost for instance %s: %w", instance.GetID(), err)
+		}
+
+		breakdown.ScannerVMHours += instanceBreakdown.ScannerVMHours
+		breakdown.SnapshotGBMonths += instanceBreakdown.SnapshotGBMonths
+		breakdown.DataTransferGB += instanceBreakdown.DataTransferGB
+		breakdown.TotalEstimatedCostUSD += instanceBreakdown.TotalEstimatedCostUSD
+	}
+
+	return breakdown, nil
+}
+
+func (e *Estimator) estimateInstance(ctx context.Context, instance types.Instance, instanceType string) (models.CostBreakdown, error) {
+	region := instance.GetRegion()
+
+	ec2HourlyPrice, err := e.ec2HourlyPrice(ctx, region, instanceType)
+	if err != nil {
+		return models.CostBreakdown{}, err
+	}
+
+	volumes, err := instance.GetVolumes(ctx)
+	if err != nil {
+		return models.CostBreakdown{}, fmt.Errorf("failed to get volumes: %w", err)
+	}
+
+	breakdown := models.CostBreakdown{
+		ScannerVMHours:        AssumedScanDurationHours,
+		TotalEstimatedCostUSD: float32(AssumedScanDurationHours * ec2HourlyPrice),
+	}
+
+	for _, volume := range volumes {
+		// A scan only needs the volume for the duration of the scan, so we
+		// project its snapshot cost over that same window rather than a
+		// full month.
+		gbMonths := float64(volume.SizeGB) * (AssumedScanDurationHours / (24 * 30))
+
+		gbMonthPrice, err := e.ebsGBMonthPrice(ctx, region, volume.VolumeType)
+		if err != nil {
+			return models.CostBreakdown{}, err
+		}
+
+		breakdown.SnapshotGBMonths += float32(gbMonths)
+		// TODO estimate DataTransferGB once we have a way to project how
+		// much of the volume a scan actually reads, and look up data-transfer
+		// pricing for it - volume.SizeGB is an EBS storage dimension, not a
+		// network one, and has no data-transfer price applied above.
+		breakdown.TotalEstimatedCostUSD += float32(gbMonths * gbMonthPrice)
+	}
+
+	return breakdown, nil
+}
@@ -0,0 +1,159 @@
+// Copyright © 2023 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package estimation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/pricing"
+	pricingtypes "github.com/aws/aws-sdk-go-v2/service/pricing/types"
+)
+
+// defaultEBSVolumeType is assumed when a volume reports no type, matching
+// the EC2 API default.
+const defaultEBSVolumeType = "gp3"
+
+func (e *Estimator) ec2HourlyPrice(ctx context.Context, region, instanceType string) (float64, error) {
+	key := priceCacheKey{region: region, resourceType: "ec2", variant: instanceType}
+	if price, ok := e.prices.get(key); ok {
+		return price, nil
+	}
+
+	price, err := e.lookUpPrice(ctx, "AmazonEC2", []pricingtypes.Filter{
+		filter("instanceType", instanceType),
+		filter("location", regionToLocation(region)),
+		filter("operatingSystem", "Linux"),
+		filter("tenancy", "Shared"),
+		filter("preInstalledSw", "NA"),
+		filter("capacitystatus", "Used"),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get EC2 on-demand price for %s in %s: %w", instanceType, region, err)
+	}
+
+	e.prices.set(key, price)
+	return price, nil
+}
+
+func (e *Estimator) ebsGBMonthPrice(ctx context.Context, region, volumeType string) (float64, error) {
+	if volumeType == "" {
+		volumeType = defaultEBSVolumeType
+	}
+
+	key := priceCacheKey{region: region, resourceType: "ebs", variant: volumeType}
+	if price, ok := e.prices.get(key); ok {
+		return price, nil
+	}
+
+	price, err := e.lookUpPrice(ctx, "AmazonEC2", []pricingtypes.Filter{
+		filter("productFamily", "Storage"),
+		filter("volumeApiName", volumeType),
+		filter("location", regionToLocation(region)),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get EBS %s GB-month price in %s: %w", volumeType, region, err)
+	}
+
+	e.prices.set(key, price)
+	return price, nil
+}
+
+func filter(field, value string) pricingtypes.Filter {
+	return pricingtypes.Filter{
+		Type:  pricingtypes.FilterTypeTermMatch,
+		Field: &field,
+		Value: &value,
+	}
+}
+
+// pricingServiceRegion is the only region the Pricing API is served from.
+const pricingServiceRegion = "us-east-1"
+
+// lookUpPrice runs a GetProducts query and extracts the USD on-demand price
+// per unit from the first matching price list entry.
+func (e *Estimator) lookUpPrice(ctx context.Context, serviceCode string, filters []pricingtypes.Filter) (float64, error) {
+	out, err := e.pricingClient.GetProducts(ctx, &pricing.GetProductsInput{
+		ServiceCode: &serviceCode,
+		Filters:     filters,
+	}, func(options *pricing.Options) {
+		options.Region = pricingServiceRegion
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get products: %w", err)
+	}
+	if len(out.PriceList) == 0 {
+		return 0, fmt.Errorf("no matching price list entries for service %s", serviceCode)
+	}
+
+	return parseOnDemandUSDPerUnit(out.PriceList[0])
+}
+
+// priceListEntry is the subset of the AWS Price List JSON document shape
+// (https://docs.aws.amazon.com/awsaccountbilling/latest/aboutv2/reading-an-offer.html)
+// that we need to pull the USD on-demand price per unit out of.
+type priceListEntry struct {
+	Terms struct {
+		OnDemand map[string]struct {
+			PriceDimensions map[string]struct {
+				PricePerUnit struct {
+					USD string `json:"USD"`
+				} `json:"pricePerUnit"`
+			} `json:"priceDimensions"`
+		} `json:"OnDemand"`
+	} `json:"terms"`
+}
+
+func parseOnDemandUSDPerUnit(raw string) (float64, error) {
+	var entry priceListEntry
+	if err := json.Unmarshal([]byte(raw), &entry); err != nil {
+		return 0, fmt.Errorf("failed to parse price list entry: %w", err)
+	}
+
+	for _, term := range entry.Terms.OnDemand {
+		for _, dimension := range term.PriceDimensions {
+			var price float64
+			if _, err := fmt.Sscanf(dimension.PricePerUnit.USD, "%f", &price); err != nil {
+				return 0, fmt.Errorf("failed to parse price %q: %w", dimension.PricePerUnit.USD, err)
+			}
+			return price, nil
+		}
+	}
+
+	return 0, fmt.Errorf("price list entry had no on-demand price dimensions")
+}
+
+// regionToLocation maps an AWS region code to the "location" value the
+// Pricing API's AmazonEC2 service uses to filter by region.
+func regionToLocation(region string) string {
+	if location, ok := regionLocations[region]; ok {
+		return location
+	}
+	return region
+}
+
+var regionLocations = map[string]string{
+	"us-east-1":      "US East (N. Virginia)",
+	"us-east-2":      "US East (Ohio)",
+	"us-west-1":      "US West (N. California)",
+	"us-west-2":      "US West (Oregon)",
+	"eu-west-1":      "EU (Ireland)",
+	"eu-central-1":   "EU (Frankfurt)",
+	"ap-southeast-1": "Asia Pacific (Singapore)",
+	"ap-southeast-2": "Asia Pacific (Sydney)",
+	"ap-northeast-1": "Asia Pacific (Tokyo)",
+}
@@ -0,0 +1,52 @@
+// Copyright © 2023 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rest
+
+import (
+	"context"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// TimeoutHeader lets a client bound how long the server may spend on a
+// single request. Its value is a Go duration string (e.g. "5s"). If absent
+// or unparsable, defaultRequestTimeout applies.
+const TimeoutHeader = "X-VMClarity-Timeout"
+
+// defaultRequestTimeout bounds handlers that don't set TimeoutHeader, so a
+// slow estimation query (e.g. across a large asset inventory) can't hang a
+// request indefinitely.
+const defaultRequestTimeout = 30 * time.Second
+
+// requestContext derives a cancellable, deadline-bound context from the
+// incoming request: the request's own context as parent, with a timeout
+// taken from TimeoutHeader or defaultRequestTimeout otherwise. It's built on
+// context.WithTimeout, which - like netstack's gonet deadline timer - arms a
+// single time.AfterFunc that closes the context's Done channel on expiry;
+// callers must invoke the returned cancel func once the operation completes
+// so that a request which finishes early frees the timer immediately instead
+// of leaking it until the deadline.
+func requestContext(ctx echo.Context) (context.Context, context.CancelFunc) {
+	timeout := defaultRequestTimeout
+	if raw := ctx.Request().Header.Get(TimeoutHeader); raw != "" {
+		if parsed, err := time.ParseDuration(raw); err == nil && parsed > 0 {
+			timeout = parsed
+		}
+	}
+
+	return context.WithTimeout(ctx.Request().Context(), timeout)
+}
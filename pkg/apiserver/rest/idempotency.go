@@ -0,0 +1,121 @@
+// Copyright © 2023 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/openclarity/vmclarity/api/models"
+	databaseTypes "github.com/openclarity/vmclarity/pkg/apiserver/database/types"
+)
+
+// IdempotencyKeyHeader lets a client mark a POST as safe to retry: a repeat
+// POST with the same key returns the original 201 response instead of
+// creating a duplicate ScanEstimation. This API has no authenticated
+// principal to additionally scope the key to - and scoping it to something
+// like the client's RemoteAddr would be actively wrong, since clients behind
+// the same proxy/NAT/load balancer share one, and a single client's own
+// retries can arrive from different ones across reconnects - so the key
+// alone identifies the request. Clients must generate a key unique to each
+// logical request (e.g. a UUID per operation).
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// replayIdempotentCreate returns the ScanEstimation created by a prior POST
+// made with this idempotencyKey, or nil if none is on record. It returns
+// databaseTypes.ErrIdempotencyKeyConflict if a record exists but the
+// resource it points at can no longer reproduce the original response.
+func (s *ServerImpl) replayIdempotentCreate(ctx context.Context, idempotencyKey string) (*models.ScanEstimation, error) {
+	record, err := s.dbHandler.IdempotencyKeysTable().Get(ctx, idempotencyKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up idempotency key: %w", err)
+	}
+	if record == nil {
+		return nil, nil
+	}
+
+	scanEstimation, err := s.dbHandler.ScanEstimationsTable().GetScanEstimation(ctx, record.ScanEstimationID, models.GetScanEstimationsScanEstimationIDParams{})
+	if err != nil {
+		if errors.Is(err, databaseTypes.ErrNotFound) {
+			return nil, fmt.Errorf("%w: %w", databaseTypes.ErrIdempotencyKeyConflict, err)
+		}
+		return nil, fmt.Errorf("failed to get ScanEstimation recorded for idempotency key: %w", err)
+	}
+
+	hash, err := hashResponse(scanEstimation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash replayed response: %w", err)
+	}
+	if hash != record.ResponseHash {
+		return nil, databaseTypes.ErrIdempotencyKeyConflict
+	}
+
+	return &scanEstimation, nil
+}
+
+// reserveIdempotentCreate atomically claims idempotencyKey so only one
+// concurrent POST sharing it can proceed to create a resource; the rest
+// observe databaseTypes.ErrIdempotencyKeyConflict. The caller must follow a
+// successful reservation with finalizeIdempotentCreate on success, or
+// releaseIdempotentKey on failure.
+func (s *ServerImpl) reserveIdempotentCreate(ctx context.Context, idempotencyKey string) error {
+	if err := s.dbHandler.IdempotencyKeysTable().Reserve(ctx, idempotencyKey); err != nil {
+		return fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// finalizeIdempotentCreate records the outcome of the POST that reserved
+// idempotencyKey, so a retry with the same key can be answered with this
+// same response.
+func (s *ServerImpl) finalizeIdempotentCreate(ctx context.Context, idempotencyKey string, created models.ScanEstimation) error {
+	hash, err := hashResponse(created)
+	if err != nil {
+		return fmt.Errorf("failed to hash response: %w", err)
+	}
+
+	return s.dbHandler.IdempotencyKeysTable().Finalize(ctx, idempotencyKey, databaseTypes.IdempotencyRecord{
+		ScanEstimationID: *created.Id,
+		ResponseHash:     hash,
+	})
+}
+
+// releaseIdempotentKey discards a reservation that was never finalized,
+// e.g. because the create it was reserved for failed, so the key is
+// immediately available for a retry instead of wedged until it expires.
+func (s *ServerImpl) releaseIdempotentKey(ctx context.Context, idempotencyKey string) error {
+	if err := s.dbHandler.IdempotencyKeysTable().Release(ctx, idempotencyKey); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// hashResponse returns the hex-encoded SHA-256 of v's JSON encoding.
+func hashResponse(v any) (string, error) {
+	marshaled, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	sum := sha256.Sum256(marshaled)
+	return hex.EncodeToString(sum[:]), nil
+}
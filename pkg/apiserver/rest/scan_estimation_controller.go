@@ -16,21 +16,34 @@
 package rest
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
 
 	"github.com/openclarity/vmclarity/api/models"
 	"github.com/openclarity/vmclarity/pkg/apiserver/common"
 	databaseTypes "github.com/openclarity/vmclarity/pkg/apiserver/database/types"
+	"github.com/openclarity/vmclarity/pkg/apiserver/webhooks"
 	"github.com/openclarity/vmclarity/pkg/shared/utils"
 )
 
+// scanEstimationResourceType is the resourceType used when raising webhook
+// events for ScanEstimation lifecycle transitions.
+const scanEstimationResourceType = "ScanEstimation"
+
 func (s *ServerImpl) GetScanEstimations(ctx echo.Context, params models.GetScanEstimationsParams) error {
-	scanEstimations, err := s.dbHandler.ScanEstimationsTable().GetScanEstimations(params)
+	reqCtx, cancel := requestContext(ctx)
+	defer cancel()
+
+	scanEstimations, err := s.dbHandler.ScanEstimationsTable().GetScanEstimations(reqCtx, params)
 	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return sendError(ctx, http.StatusGatewayTimeout, "request exceeded its deadline")
+		}
 		return sendError(ctx, http.StatusInternalServerError, fmt.Sprintf("failed to get scan estimations from db: %v", err))
 	}
 
@@ -38,16 +51,50 @@ func (s *ServerImpl) GetScanEstimations(ctx echo.Context, params models.GetScanE
 }
 
 func (s *ServerImpl) PostScanEstimations(ctx echo.Context) error {
+	reqCtx, cancel := requestContext(ctx)
+	defer cancel()
+
 	var scanEstimation models.ScanEstimation
 	err := ctx.Bind(&scanEstimation)
 	if err != nil {
 		return sendError(ctx, http.StatusBadRequest, fmt.Sprintf("failed to bind request: %v", err))
 	}
 
-	createdScanEstimation, err := s.dbHandler.ScanEstimationsTable().CreateScanEstimation(scanEstimation)
+	idempotencyKey := ctx.Request().Header.Get(IdempotencyKeyHeader)
+
+	if idempotencyKey != "" {
+		if replayed, err := s.replayIdempotentCreate(reqCtx, idempotencyKey); err != nil {
+			if errors.Is(err, databaseTypes.ErrIdempotencyKeyConflict) {
+				return sendError(ctx, http.StatusConflict, err.Error())
+			}
+			return sendError(ctx, http.StatusInternalServerError, fmt.Sprintf("failed to look up idempotency key: %v", err))
+		} else if replayed != nil {
+			return sendResponse(ctx, http.StatusCreated, *replayed)
+		}
+
+		// Claim the key before creating anything, so a concurrent POST
+		// sharing it can't also pass the check above and create its own
+		// ScanEstimation.
+		if err := s.reserveIdempotentCreate(reqCtx, idempotencyKey); err != nil {
+			if errors.Is(err, databaseTypes.ErrIdempotencyKeyConflict) {
+				return sendError(ctx, http.StatusConflict, "a request with this Idempotency-Key is already in progress")
+			}
+			return sendError(ctx, http.StatusInternalServerError, fmt.Sprintf("failed to reserve idempotency key: %v", err))
+		}
+	}
+
+	createdScanEstimation, err := s.dbHandler.ScanEstimationsTable().CreateScanEstimation(reqCtx, scanEstimation)
 	if err != nil {
+		if idempotencyKey != "" {
+			if releaseErr := s.releaseIdempotentKey(reqCtx, idempotencyKey); releaseErr != nil {
+				log.Errorf("failed to release idempotency key %s after failed create: %v", idempotencyKey, releaseErr)
+			}
+		}
+
 		var validationErr *common.BadRequestError
 		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			return sendError(ctx, http.StatusGatewayTimeout, "request exceeded its deadline")
 		case errors.As(err, &validationErr):
 			return sendError(ctx, http.StatusBadRequest, err.Error())
 		default:
@@ -55,36 +102,87 @@ func (s *ServerImpl) PostScanEstimations(ctx echo.Context) error {
 		}
 	}
 
+	if idempotencyKey != "" {
+		if err := s.finalizeIdempotentCreate(reqCtx, idempotencyKey, createdScanEstimation); err != nil {
+			log.Errorf("failed to finalize idempotency key for ScanEstimation %v: %v", *createdScanEstimation.Id, err)
+		}
+	}
+
+	s.enqueueWebhookEvent(scanEstimationResourceType, webhooks.EventCreated, createdScanEstimation)
+
 	return sendResponse(ctx, http.StatusCreated, createdScanEstimation)
 }
 
+// PostScanEstimationsScanEstimationIDRefresh re-runs the cost estimator
+// against the ScanEstimation's existing scope and persists the updated
+// CostBreakdown, without changing the scope itself.
+func (s *ServerImpl) PostScanEstimationsScanEstimationIDRefresh(ctx echo.Context, scanEstimationID models.ScanEstimationID) error {
+	reqCtx, cancel := requestContext(ctx)
+	defer cancel()
+
+	refreshedScanEstimation, err := s.dbHandler.ScanEstimationsTable().RefreshScanEstimation(reqCtx, scanEstimationID)
+	if err != nil {
+		switch {
+		case errors.Is(err, databaseTypes.ErrNotFound):
+			return sendError(ctx, http.StatusNotFound, fmt.Sprintf("ScanEstimation with ID %v not found", scanEstimationID))
+		case errors.Is(err, context.DeadlineExceeded):
+			return sendError(ctx, http.StatusGatewayTimeout, "request exceeded its deadline")
+		default:
+			return sendError(ctx, http.StatusInternalServerError, fmt.Sprintf("failed to refresh scan estimation. scanEstimationID=%v: %v", scanEstimationID, err))
+		}
+	}
+
+	s.enqueueWebhookEvent(scanEstimationResourceType, webhooks.EventSucceeded, refreshedScanEstimation)
+
+	return sendResponse(ctx, http.StatusOK, refreshedScanEstimation)
+}
+
 func (s *ServerImpl) DeleteScanEstimationsScanEstimationID(ctx echo.Context, scanEstimationID models.ScanEstimationID) error {
+	reqCtx, cancel := requestContext(ctx)
+	defer cancel()
+
 	success := models.Success{
 		Message: utils.PointerTo(fmt.Sprintf("scan estimation %v deleted", scanEstimationID)),
 	}
 
-	if err := s.dbHandler.ScanEstimationsTable().DeleteScanEstimation(scanEstimationID); err != nil {
-		if errors.Is(err, databaseTypes.ErrNotFound) {
+	if err := s.dbHandler.ScanEstimationsTable().DeleteScanEstimation(reqCtx, scanEstimationID); err != nil {
+		switch {
+		case errors.Is(err, databaseTypes.ErrNotFound):
 			return sendError(ctx, http.StatusNotFound, fmt.Sprintf("ScanEstimation with ID %v not found", scanEstimationID))
+		case errors.Is(err, context.DeadlineExceeded):
+			return sendError(ctx, http.StatusGatewayTimeout, "request exceeded its deadline")
+		default:
+			return sendError(ctx, http.StatusInternalServerError, fmt.Sprintf("failed to delete scan estimation from db. scanEstimationID=%v: %v", scanEstimationID, err))
 		}
-		return sendError(ctx, http.StatusInternalServerError, fmt.Sprintf("failed to delete scan estimation from db. scanEstimationID=%v: %v", scanEstimationID, err))
 	}
 
+	s.enqueueWebhookEvent(scanEstimationResourceType, webhooks.EventDeleted, &models.ScanEstimation{Id: &scanEstimationID})
+
 	return sendResponse(ctx, http.StatusOK, &success)
 }
 
 func (s *ServerImpl) GetScanEstimationsScanEstimationID(ctx echo.Context, scanEstimationID models.ScanEstimationID, params models.GetScanEstimationsScanEstimationIDParams) error {
-	scanEstimation, err := s.dbHandler.ScanEstimationsTable().GetScanEstimation(scanEstimationID, params)
+	reqCtx, cancel := requestContext(ctx)
+	defer cancel()
+
+	scanEstimation, err := s.dbHandler.ScanEstimationsTable().GetScanEstimation(reqCtx, scanEstimationID, params)
 	if err != nil {
-		if errors.Is(err, databaseTypes.ErrNotFound) {
+		switch {
+		case errors.Is(err, databaseTypes.ErrNotFound):
 			return sendError(ctx, http.StatusNotFound, fmt.Sprintf("ScanEstimation with ID %v not found", scanEstimationID))
+		case errors.Is(err, context.DeadlineExceeded):
+			return sendError(ctx, http.StatusGatewayTimeout, "request exceeded its deadline")
+		default:
+			return sendError(ctx, http.StatusInternalServerError, fmt.Sprintf("failed to get scan estimation from db. id=%v: %v", scanEstimationID, err))
 		}
-		return sendError(ctx, http.StatusInternalServerError, fmt.Sprintf("failed to get scan estimation from db. id=%v: %v", scanEstimationID, err))
 	}
 	return sendResponse(ctx, http.StatusOK, scanEstimation)
 }
 
 func (s *ServerImpl) PatchScanEstimationsScanEstimationID(ctx echo.Context, scanEstimationID models.ScanEstimationID, params models.PatchScanEstimationsScanEstimationIDParams) error {
+	reqCtx, cancel := requestContext(ctx)
+	defer cancel()
+
 	var scanEstimation models.ScanEstimation
 	err := ctx.Bind(&scanEstimation)
 	if err != nil {
@@ -98,13 +196,15 @@ func (s *ServerImpl) PatchScanEstimationsScanEstimationID(ctx echo.Context, scan
 	}
 	scanEstimation.Id = &scanEstimationID
 
-	updatedScanEstimation, err := s.dbHandler.ScanEstimationsTable().UpdateScanEstimation(scanEstimation, params)
+	updatedScanEstimation, err := s.dbHandler.ScanEstimationsTable().UpdateScanEstimation(reqCtx, scanEstimation, params)
 	if err != nil {
 		var validationErr *common.BadRequestError
 		var preconditionFailedErr *databaseTypes.PreconditionFailedError
 		switch {
 		case errors.Is(err, databaseTypes.ErrNotFound):
 			return sendError(ctx, http.StatusNotFound, fmt.Sprintf("ScanEstimation with ID %v not found", scanEstimationID))
+		case errors.Is(err, context.DeadlineExceeded):
+			return sendError(ctx, http.StatusGatewayTimeout, "request exceeded its deadline")
 		case errors.As(err, &validationErr):
 			return sendError(ctx, http.StatusBadRequest, err.Error())
 		case errors.As(err, &preconditionFailedErr):
@@ -114,10 +214,15 @@ func (s *ServerImpl) PatchScanEstimationsScanEstimationID(ctx echo.Context, scan
 		}
 	}
 
+	s.enqueueWebhookEvent(scanEstimationResourceType, webhooks.EventSucceeded, updatedScanEstimation)
+
 	return sendResponse(ctx, http.StatusOK, updatedScanEstimation)
 }
 
 func (s *ServerImpl) PutScanEstimationsScanEstimationID(ctx echo.Context, scanEstimationID models.ScanEstimationID, params models.PutScanEstimationsScanEstimationIDParams) error {
+	reqCtx, cancel := requestContext(ctx)
+	defer cancel()
+
 	var scanEstimation models.ScanEstimation
 	err := ctx.Bind(&scanEstimation)
 	if err != nil {
@@ -131,13 +236,15 @@ func (s *ServerImpl) PutScanEstimationsScanEstimationID(ctx echo.Context, scanEs
 	}
 	scanEstimation.Id = &scanEstimationID
 
-	updatedScanEstimation, err := s.dbHandler.ScanEstimationsTable().SaveScanEstimation(scanEstimation, params)
+	updatedScanEstimation, err := s.dbHandler.ScanEstimationsTable().SaveScanEstimation(reqCtx, scanEstimation, params)
 	if err != nil {
 		var validationErr *common.BadRequestError
 		var preconditionFailedErr *databaseTypes.PreconditionFailedError
 		switch {
 		case errors.Is(err, databaseTypes.ErrNotFound):
 			return sendError(ctx, http.StatusNotFound, fmt.Sprintf("ScanEstimation with ID %v not found", scanEstimationID))
+		case errors.Is(err, context.DeadlineExceeded):
+			return sendError(ctx, http.StatusGatewayTimeout, "request exceeded its deadline")
 		case errors.As(err, &validationErr):
 			return sendError(ctx, http.StatusBadRequest, err.Error())
 		case errors.As(err, &preconditionFailedErr):
@@ -147,5 +254,7 @@ func (s *ServerImpl) PutScanEstimationsScanEstimationID(ctx echo.Context, scanEs
 		}
 	}
 
+	s.enqueueWebhookEvent(scanEstimationResourceType, webhooks.EventSucceeded, updatedScanEstimation)
+
 	return sendResponse(ctx, http.StatusOK, updatedScanEstimation)
 }
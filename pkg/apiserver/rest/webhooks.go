@@ -0,0 +1,41 @@
+// Copyright © 2023 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rest
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openclarity/vmclarity/pkg/apiserver/webhooks"
+)
+
+// enqueueWebhookEvent builds an event envelope for resourceType/eventType and
+// enqueues it for delivery on s.dispatcher. It's a no-op if no dispatcher is
+// configured. Failures to build the envelope are logged rather than
+// returned, since a webhook delivery problem must never fail the HTTP
+// request that raised it.
+func (s *ServerImpl) enqueueWebhookEvent(resourceType string, eventType webhooks.EventType, resource any) {
+	if s.dispatcher == nil {
+		return
+	}
+
+	event, err := webhooks.NewEvent(resourceType, eventType, resource)
+	if err != nil {
+		log.Errorf("failed to build %s webhook event for %s: %v", eventType, resourceType, err)
+		return
+	}
+
+	s.dispatcher.Enqueue(event)
+}
@@ -0,0 +1,222 @@
+// Copyright © 2023 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package webhooks lets external systems subscribe to resource lifecycle
+// events (created, started, progress-updated, succeeded, failed, deleted)
+// raised by the API server. The REST handlers enqueue events after their DB
+// commit succeeds, so delivering webhooks never adds latency to the HTTP
+// response.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+// EventType identifies the lifecycle transition a webhook event describes.
+type EventType string
+
+const (
+	EventCreated         EventType = "created"
+	EventStarted         EventType = "started"
+	EventProgressUpdated EventType = "progress_updated"
+	EventSucceeded       EventType = "succeeded"
+	EventFailed          EventType = "failed"
+	EventDeleted         EventType = "deleted"
+)
+
+// SignatureHeader carries the hex-encoded HMAC-SHA256 signature of the
+// request body, computed with the subscriber's secret.
+const SignatureHeader = "X-VMClarity-Signature"
+
+// Event is the envelope delivered to subscribers: an event ID, its type, the
+// time it was raised, and a snapshot of the resource it concerns.
+type Event struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	Timestamp time.Time       `json:"timestamp"`
+	Resource  json.RawMessage `json:"resource"`
+}
+
+// NewEvent builds an Event envelope for resourceType (e.g. "ScanEstimation")
+// transitioning to eventType, snapshotting resource as JSON.
+func NewEvent(resourceType string, eventType EventType, resource any) (Event, error) {
+	snapshot, err := json.Marshal(resource)
+	if err != nil {
+		return Event{}, fmt.Errorf("failed to marshal event resource snapshot: %w", err)
+	}
+
+	return Event{
+		ID:        uuid.New().String(),
+		Type:      fmt.Sprintf("%s.%s", resourceType, eventType),
+		Timestamp: time.Now(),
+		Resource:  snapshot,
+	}, nil
+}
+
+// Subscriber is a registered webhook endpoint.
+type Subscriber struct {
+	ID     string
+	URL    string
+	Secret string
+}
+
+// SubscriberStore resolves the subscribers registered for delivery.
+type SubscriberStore interface {
+	ListSubscribers(ctx context.Context) ([]Subscriber, error)
+}
+
+// DeliveryRecorder persists the outcome of a delivery attempt for auditing.
+type DeliveryRecorder interface {
+	RecordDeliveryAttempt(ctx context.Context, eventID, subscriberID string, attempt int, statusCode int, deliveryErr error) error
+}
+
+const (
+	// eventQueueSize bounds how many undelivered events the dispatcher
+	// buffers before Enqueue starts dropping events rather than blocking
+	// the caller.
+	eventQueueSize = 1024
+
+	maxDeliveryAttempts = 5
+	baseRetryBackoff    = 2 * time.Second
+)
+
+// Dispatcher delivers Events to every registered Subscriber, retrying
+// failed deliveries with exponential backoff.
+type Dispatcher struct {
+	store      SubscriberStore
+	recorder   DeliveryRecorder
+	httpClient *http.Client
+	events     chan Event
+}
+
+func NewDispatcher(store SubscriberStore, recorder DeliveryRecorder) *Dispatcher {
+	return &Dispatcher{
+		store:      store,
+		recorder:   recorder,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		events:     make(chan Event, eventQueueSize),
+	}
+}
+
+// Enqueue schedules event for delivery without blocking the caller. If the
+// queue is full the event is dropped and logged, so a slow or down
+// subscriber can never add latency to the HTTP response that raised it.
+func (d *Dispatcher) Enqueue(event Event) {
+	select {
+	case d.events <- event:
+	default:
+		log.Errorf("webhook event queue full, dropping event %s (%s)", event.ID, event.Type)
+	}
+}
+
+// Run drains the event queue and delivers each event to every subscriber,
+// blocking until ctx is cancelled. Callers should run it in a goroutine.
+func (d *Dispatcher) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-d.events:
+			d.dispatch(ctx, event)
+		}
+	}
+}
+
+func (d *Dispatcher) dispatch(ctx context.Context, event Event) {
+	subscribers, err := d.store.ListSubscribers(ctx)
+	if err != nil {
+		log.Errorf("failed to list webhook subscribers for event %s: %v", event.ID, err)
+		return
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Errorf("failed to marshal webhook event %s: %v", event.ID, err)
+		return
+	}
+
+	for _, subscriber := range subscribers {
+		subscriber := subscriber
+		go d.deliverWithRetry(ctx, subscriber, event.ID, body)
+	}
+}
+
+func (d *Dispatcher) deliverWithRetry(ctx context.Context, subscriber Subscriber, eventID string, body []byte) {
+	var lastErr error
+	for attempt := 1; attempt <= maxDeliveryAttempts; attempt++ {
+		statusCode, err := d.deliver(ctx, subscriber, body)
+		if recordErr := d.recorder.RecordDeliveryAttempt(ctx, eventID, subscriber.ID, attempt, statusCode, err); recordErr != nil {
+			log.Errorf("failed to record webhook delivery attempt for event %s: %v", eventID, recordErr)
+		}
+		if err == nil {
+			return
+		}
+		lastErr = err
+
+		if attempt == maxDeliveryAttempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(baseRetryBackoff * time.Duration(1<<uint(attempt-1))): //nolint:gosec
+		}
+	}
+
+	log.Errorf("giving up delivering webhook event %s to subscriber %s after %d attempts: %v",
+		eventID, subscriber.ID, maxDeliveryAttempts, lastErr)
+}
+
+// deliver POSTs body to subscriber.URL, HMAC-signing it with the
+// subscriber's secret, and returns the response status code (0 if the
+// request never got a response).
+func (d *Dispatcher) deliver(ctx context.Context, subscriber Subscriber, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscriber.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, sign(subscriber.Secret, body))
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to deliver webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("subscriber returned status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// sign returns the hex-encoded HMAC-SHA256 of body using secret.
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
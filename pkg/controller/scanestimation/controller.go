@@ -0,0 +1,266 @@
+// Copyright © 2023 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package scanestimation reconciles apps.vmclarity.io/v1 ScanEstimation CRs
+// against the same ScanEstimationsTable the REST API's
+// PostScanEstimations/PatchScanEstimationsScanEstimationID handlers use, so
+// platform teams can declare estimation policies as Kubernetes objects and
+// GitOps them alongside their other workloads. Like those handlers, it
+// enqueues webhook events on Dispatcher after each DB commit succeeds, so
+// CR-driven changes raise the same lifecycle events HTTP-driven ones do.
+package scanestimation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/openclarity/vmclarity/api/models"
+	appsv1 "github.com/openclarity/vmclarity/api/v1"
+	databaseTypes "github.com/openclarity/vmclarity/pkg/apiserver/database/types"
+	"github.com/openclarity/vmclarity/pkg/apiserver/estimation"
+	"github.com/openclarity/vmclarity/pkg/apiserver/webhooks"
+)
+
+// scanEstimationResourceType is the resourceType used when raising webhook
+// events for ScanEstimation lifecycle transitions, matching the REST
+// handlers' scanEstimationResourceType.
+const scanEstimationResourceType = "ScanEstimation"
+
+// scanEstimationFinalizer is set on every ScanEstimation CR this controller
+// has created a DB-backed counterpart for, so its deletion can call
+// Table.DeleteScanEstimation before the CR is allowed to go away.
+const scanEstimationFinalizer = "apps.vmclarity.io/scanestimation-finalizer"
+
+// Reconciler reconciles a ScanEstimation CR against Table, keeping the CR's
+// .status in sync with the row Table reads and writes.
+type Reconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// Table is the same persistence interface the REST handlers use;
+	// reconciling a CR calls exactly the methods PostScanEstimations and
+	// PatchScanEstimationsScanEstimationID do.
+	Table databaseTypes.ScanEstimationsTable
+
+	// Dispatcher raises the same lifecycle webhook events the REST
+	// handlers do. It's nil until set during controller setup, in which
+	// case reconciliation skips event dispatch entirely.
+	Dispatcher *webhooks.Dispatcher
+}
+
+// enqueueWebhookEvent builds an event envelope for eventType and enqueues it
+// for delivery. It's a no-op if no dispatcher is configured; failures to
+// build the envelope are logged rather than returned, since a webhook
+// delivery problem must never fail reconciliation.
+func (r *Reconciler) enqueueWebhookEvent(eventType webhooks.EventType, resource models.ScanEstimation) {
+	if r.Dispatcher == nil {
+		return
+	}
+
+	event, err := webhooks.NewEvent(scanEstimationResourceType, eventType, resource)
+	if err != nil {
+		ctrl.Log.Error(err, "failed to build webhook event", "eventType", eventType)
+		return
+	}
+
+	r.Dispatcher.Enqueue(event)
+}
+
+// +kubebuilder:rbac:groups=apps.vmclarity.io,resources=scanestimations,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps.vmclarity.io,resources=scanestimations/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=apps.vmclarity.io,resources=scanestimations/finalizers,verbs=update
+// +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
+
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	var cr appsv1.ScanEstimation
+	if err := r.Get(ctx, req.NamespacedName, &cr); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("failed to get ScanEstimation %s: %w", req.NamespacedName, err)
+	}
+
+	if !cr.DeletionTimestamp.IsZero() {
+		return r.reconcileDelete(ctx, &cr)
+	}
+
+	if !controllerutil.ContainsFinalizer(&cr, scanEstimationFinalizer) {
+		controllerutil.AddFinalizer(&cr, scanEstimationFinalizer)
+		if err := r.Update(ctx, &cr); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to add finalizer to ScanEstimation %s: %w", req.NamespacedName, err)
+		}
+	}
+
+	if !validDeliveryMode(cr.Spec.DeliveryMode) {
+		return r.failStatus(ctx, &cr, fmt.Errorf("unsupported deliveryMode %q", cr.Spec.DeliveryMode))
+	}
+
+	result, err := r.reconcileScanEstimation(ctx, &cr)
+	if err != nil {
+		return r.failStatus(ctx, &cr, err)
+	}
+
+	if err := r.reconcileDeliveryService(ctx, &cr); err != nil {
+		return r.failStatus(ctx, &cr, err)
+	}
+
+	cr.Status.Phase = appsv1.PhaseReady
+	cr.Status.ScanEstimationID = *result.Id
+	cr.Status.LastError = ""
+	cr.Status.ObservedGeneration = cr.Generation
+	if result.Estimation != nil && result.Estimation.Cost != nil {
+		cr.Status.EstimatedCostUSD = &result.Estimation.Cost.TotalEstimatedCostUSD
+	}
+	// The estimator prices every target against the same assumed scan
+	// runtime regardless of how many targets it found, since they're
+	// scanned in parallel - so that constant is the projected wall-clock
+	// duration, not ScannerVMHours (which scales with target count).
+	cr.Status.EstimatedDuration = &metav1.Duration{
+		Duration: time.Duration(estimation.AssumedScanDurationHours * float64(time.Hour)),
+	}
+
+	if err := r.Status().Update(ctx, &cr); err != nil {
+		if apierrors.IsConflict(err) {
+			return ctrl.Result{Requeue: true}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("failed to update ScanEstimation %s status: %w", req.NamespacedName, err)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// reconcileScanEstimation creates or updates the DB-backed ScanEstimation
+// this CR owns, depending on whether Status.ScanEstimationID is already set.
+func (r *Reconciler) reconcileScanEstimation(ctx context.Context, cr *appsv1.ScanEstimation) (models.ScanEstimation, error) {
+	if cr.Status.ScanEstimationID == "" {
+		created, err := r.Table.CreateScanEstimation(ctx, models.ScanEstimation{Scope: cr.Spec.Scope})
+		if err != nil {
+			return models.ScanEstimation{}, fmt.Errorf("failed to create scan estimation: %w", err)
+		}
+		r.enqueueWebhookEvent(webhooks.EventCreated, created)
+		return created, nil
+	}
+
+	updated, err := r.Table.UpdateScanEstimation(ctx, models.ScanEstimation{
+		Id:    &cr.Status.ScanEstimationID,
+		Scope: cr.Spec.Scope,
+	}, models.PatchScanEstimationsScanEstimationIDParams{})
+	if err != nil {
+		return models.ScanEstimation{}, fmt.Errorf("failed to update scan estimation %s: %w", cr.Status.ScanEstimationID, err)
+	}
+	r.enqueueWebhookEvent(webhooks.EventSucceeded, updated)
+
+	return updated, nil
+}
+
+// reconcileDeliveryService ensures the Service fronting cr's scan delivery
+// endpoint exists with the spec its DeliveryMode implies, owned by cr so it's
+// garbage-collected when cr is deleted.
+func (r *Reconciler) reconcileDeliveryService(ctx context.Context, cr *appsv1.ScanEstimation) error {
+	svc := buildDeliveryService(cr)
+	if err := controllerutil.SetControllerReference(cr, svc, r.Scheme); err != nil {
+		return fmt.Errorf("failed to set owner reference on delivery service: %w", err)
+	}
+
+	if err := r.Create(ctx, svc); err != nil {
+		if !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create delivery service: %w", err)
+		}
+
+		var existing corev1.Service
+		if err := r.Get(ctx, client.ObjectKeyFromObject(svc), &existing); err != nil {
+			return fmt.Errorf("failed to get existing delivery service: %w", err)
+		}
+
+		// ResourceVersion is required for Update, and ClusterIP is
+		// immutable once assigned - both have to come from the object
+		// actually stored in the API server, not the freshly built one.
+		svc.ResourceVersion = existing.ResourceVersion
+		svc.Spec.ClusterIP = existing.Spec.ClusterIP
+		svc.Spec.ClusterIPs = existing.Spec.ClusterIPs
+
+		if err := r.Update(ctx, svc); err != nil {
+			return fmt.Errorf("failed to update delivery service: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// reconcileDelete deletes the DB-backed ScanEstimation this CR owns, then
+// releases the finalizer so the CR itself can be garbage-collected; the
+// owned delivery Service is removed by Kubernetes via its owner reference.
+func (r *Reconciler) reconcileDelete(ctx context.Context, cr *appsv1.ScanEstimation) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(cr, scanEstimationFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	if cr.Status.ScanEstimationID != "" {
+		scanEstimationID := cr.Status.ScanEstimationID
+		if err := r.Table.DeleteScanEstimation(ctx, scanEstimationID); err != nil && !errors.Is(err, databaseTypes.ErrNotFound) {
+			return r.failStatus(ctx, cr, fmt.Errorf("failed to delete scan estimation %s: %w", scanEstimationID, err))
+		}
+		r.enqueueWebhookEvent(webhooks.EventDeleted, models.ScanEstimation{Id: &scanEstimationID})
+	}
+
+	controllerutil.RemoveFinalizer(cr, scanEstimationFinalizer)
+	if err := r.Update(ctx, cr); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to remove finalizer from ScanEstimation %s: %w", cr.Name, err)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// failStatus records err on cr.Status and requeues; status update conflicts
+// are swallowed into a plain requeue rather than surfaced as reconcile
+// errors, since the next attempt will retry against the now-current object.
+func (r *Reconciler) failStatus(ctx context.Context, cr *appsv1.ScanEstimation, reconcileErr error) (ctrl.Result, error) {
+	cr.Status.Phase = appsv1.PhaseFailed
+	cr.Status.LastError = reconcileErr.Error()
+	cr.Status.ObservedGeneration = cr.Generation
+
+	if err := r.Status().Update(ctx, cr); err != nil {
+		if apierrors.IsConflict(err) {
+			return ctrl.Result{Requeue: true}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("failed to update ScanEstimation %s status: %w", cr.Name, err)
+	}
+
+	return ctrl.Result{}, reconcileErr
+}
+
+// SetupWithManager registers the controller with mgr, watching
+// ScanEstimation CRs and the delivery Services it owns.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	err := ctrl.NewControllerManagedBy(mgr).
+		For(&appsv1.ScanEstimation{}).
+		Owns(&corev1.Service{}).
+		Complete(r)
+	if err != nil {
+		return fmt.Errorf("failed to set up ScanEstimation controller: %w", err)
+	}
+
+	return nil
+}
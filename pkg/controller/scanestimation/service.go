@@ -0,0 +1,67 @@
+// Copyright © 2023 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scanestimation
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	appsv1 "github.com/openclarity/vmclarity/api/v1"
+)
+
+// scanDeliveryPort is the port scanner instances expose their delivery
+// endpoint on, behind the Service buildDeliveryService renders: NodePort for
+// DeliveryModeNodePort, ClusterIP otherwise. No Ingress object is created.
+const scanDeliveryPort = 8443
+
+// validDeliveryMode reports whether mode is one of appsv1.SupportedDeliveryModes.
+func validDeliveryMode(mode appsv1.DeliveryMode) bool {
+	for _, supported := range appsv1.SupportedDeliveryModes {
+		if mode == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// buildDeliveryService renders the Service that fronts cr's eventual scan
+// delivery endpoint, templated from cr.Spec.DeliveryMode. The caller is
+// responsible for setting the owner reference and creating/updating it.
+func buildDeliveryService(cr *appsv1.ScanEstimation) *corev1.Service {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-delivery", cr.Name),
+			Namespace: cr.Namespace,
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{"apps.vmclarity.io/scan-estimation": cr.Name},
+			Ports: []corev1.ServicePort{
+				{Name: "delivery", Port: scanDeliveryPort, TargetPort: intstr.FromInt(scanDeliveryPort)},
+			},
+		},
+	}
+
+	if cr.Spec.DeliveryMode == appsv1.DeliveryModeNodePort {
+		svc.Spec.Type = corev1.ServiceTypeNodePort
+	} else {
+		svc.Spec.Type = corev1.ServiceTypeClusterIP
+	}
+
+	return svc
+}
@@ -0,0 +1,65 @@
+// Copyright © 2022 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aws
+
+import "time"
+
+// Config holds the configuration needed by the AWS provider to discover
+// assets and run scanner jobs.
+type Config struct {
+	// SubnetID is the subnet that the scanner job instances will be
+	// attached to.
+	SubnetID string
+	// SecurityGroupID is the security group that will be attached to the
+	// scanner job instances.
+	SecurityGroupID string
+	// AmiID is the image that will be used to create the scanner job
+	// instances.
+	AmiID string
+
+	// MaxConcurrentRegions bounds how many regions without an explicit VPC
+	// list Discover will scan in parallel. Regions scoped to specific VPCs
+	// are scanned per VPC and are bounded by MaxConcurrentVPCs only. A
+	// value <= 0 uses the provider's built-in default.
+	MaxConcurrentRegions int
+	// MaxConcurrentVPCs bounds how many per-VPC scans Discover runs in
+	// parallel, counted across all regions together rather than per
+	// region. A value <= 0 uses the provider's built-in default.
+	MaxConcurrentVPCs int
+
+	// UseSpot requests scanner job instances on spot capacity instead of
+	// on-demand, falling back to on-demand when spot capacity isn't
+	// available. Can be overridden per job via ScanningJobConfig.
+	UseSpot bool
+	// MaxSpotPrice is the maximum hourly price (in USD) to bid for spot
+	// capacity. Empty means the on-demand price is used as the cap.
+	MaxSpotPrice string
+	// SpotInterruptionBehavior controls what EC2 does with the instance
+	// when it's interrupted: "terminate", "stop", or "hibernate".
+	SpotInterruptionBehavior string
+
+	// StackName, if set, makes the provider manage its own scanner
+	// networking (VPC, subnet, security group, IAM instance profile) via
+	// a CloudFormation stack of this name, resolving SubnetID,
+	// SecurityGroupID and AmiID from the stack's outputs instead of
+	// requiring them to be pre-created and set above.
+	StackName string
+
+	// QuotaCacheTTL bounds how long a Service Quotas/DescribeInstanceTypes
+	// lookup is reused before checkCapacity goes back to the API. A value
+	// <= 0 uses the provider's built-in default.
+	QuotaCacheTTL time.Duration
+}
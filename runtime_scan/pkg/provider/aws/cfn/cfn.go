@@ -0,0 +1,255 @@
+// Copyright © 2022 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cfn provisions and owns the AWS provider's scanner networking
+// (VPC, subnet, security group and IAM instance profile) through a single
+// CloudFormation stack; the built-in template declares no KMS key and has no
+// option to add one. This lets multi-region scanning
+// bootstrap itself instead of requiring operators to pre-create resources in
+// every region returned by Client.ListAllRegions.
+package cfn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	cfntypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go"
+)
+
+// driftDetectionPollInterval/Timeout bound how long Manager.DetectDrift waits
+// for an asynchronous DetectStackDrift operation to finish.
+const (
+	driftDetectionPollInterval = 5 * time.Second
+	driftDetectionTimeout      = 2 * time.Minute
+)
+
+// driftCacheTTL bounds how long a drift detection result is reused before
+// DetectDrift goes back to CloudFormation, the same TTL-cache treatment
+// quotaCache gives quota lookups: scanner-job launches are frequent and
+// DetectStackDrift is both slow (up to driftDetectionTimeout) and limited to
+// one run in flight per stack, so checking on every launch serializes (or
+// outright fails) concurrent launches for no benefit, since the stack can't
+// have drifted again within the last driftCacheTTL.
+const driftCacheTTL = 5 * time.Minute
+
+// driftCacheEntry holds the last successful DetectDrift result.
+type driftCacheEntry struct {
+	drifted   bool
+	expiresAt time.Time
+}
+
+// Outputs holds the scanner networking resources produced by the stack,
+// resolved into the shape aws.Config needs.
+type Outputs struct {
+	SubnetID        string
+	SecurityGroupID string
+	AmiID           string
+}
+
+const (
+	outputSubnetID        = "SubnetID"
+	outputSecurityGroupID = "SecurityGroupID"
+	outputAmiID           = "AmiID"
+)
+
+// Manager owns the CloudFormation stack backing a region's scanner
+// infrastructure.
+type Manager struct {
+	cfnClient *cloudformation.Client
+	stackName string
+
+	// driftMu guards driftCache and serializes drift-detection runs, so
+	// concurrent DetectDrift callers can never start two DetectStackDrift
+	// operations against the same stack at once.
+	driftMu    sync.Mutex
+	driftCache *driftCacheEntry
+}
+
+func New(cfnClient *cloudformation.Client, stackName string) *Manager {
+	return &Manager{
+		cfnClient: cfnClient,
+		stackName: stackName,
+	}
+}
+
+// EnsureStack creates the scanner infrastructure stack if it doesn't exist
+// yet, or updates it in place (a no-op update is tolerated) if it does.
+func (m *Manager) EnsureStack(ctx context.Context, templateBody string, parameters map[string]string) error {
+	exists, err := m.stackExists(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check if stack %s exists: %w", m.stackName, err)
+	}
+
+	if !exists {
+		_, err := m.cfnClient.CreateStack(ctx, buildCreateStackInput(m.stackName, templateBody, parameters))
+		if err != nil {
+			return fmt.Errorf("failed to create stack %s: %w", m.stackName, err)
+		}
+		return nil
+	}
+
+	_, err = m.cfnClient.UpdateStack(ctx, buildUpdateStackInput(m.stackName, templateBody, parameters))
+	if err != nil {
+		// CloudFormation reports a no-op update as a plain ValidationError
+		// with this message rather than a distinct error type.
+		if apiErrorCode(err) == "ValidationError" && strings.Contains(err.Error(), "No updates are to be performed") {
+			return nil
+		}
+		return fmt.Errorf("failed to update stack %s: %w", m.stackName, err)
+	}
+	return nil
+}
+
+// EnsureDefaultStack is EnsureStack against scannerInfraTemplate, the
+// built-in template for the scanner networking resources Outputs reads.
+// Callers that need to customize the networking (non-default CIDRs, a
+// pre-built AMI, ...) should call EnsureStack directly with their own
+// template instead.
+func (m *Manager) EnsureDefaultStack(ctx context.Context) error {
+	return m.EnsureStack(ctx, scannerInfraTemplate, nil)
+}
+
+func (m *Manager) stackExists(ctx context.Context) (bool, error) {
+	_, err := m.cfnClient.DescribeStacks(ctx, &cloudformation.DescribeStacksInput{
+		StackName: &m.stackName,
+	})
+	if err != nil {
+		if apiErrorCode(err) == "ValidationError" && strings.Contains(err.Error(), "does not exist") {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// apiErrorCode extracts the AWS error code from err, if any.
+func apiErrorCode(err error) string {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return ""
+	}
+	return apiErr.ErrorCode()
+}
+
+// Outputs resolves the stack's SubnetID/SecurityGroupID/AmiID outputs.
+func (m *Manager) Outputs(ctx context.Context) (Outputs, error) {
+	out, err := m.cfnClient.DescribeStacks(ctx, &cloudformation.DescribeStacksInput{
+		StackName: &m.stackName,
+	})
+	if err != nil {
+		return Outputs{}, fmt.Errorf("failed to describe stack %s: %w", m.stackName, err)
+	}
+	if len(out.Stacks) == 0 {
+		return Outputs{}, fmt.Errorf("stack %s not found", m.stackName)
+	}
+
+	var outputs Outputs
+	for _, output := range out.Stacks[0].Outputs {
+		if output.OutputKey == nil || output.OutputValue == nil {
+			continue
+		}
+		switch *output.OutputKey {
+		case outputSubnetID:
+			outputs.SubnetID = *output.OutputValue
+		case outputSecurityGroupID:
+			outputs.SecurityGroupID = *output.OutputValue
+		case outputAmiID:
+			outputs.AmiID = *output.OutputValue
+		}
+	}
+
+	return outputs, nil
+}
+
+// DetectDrift reports whether the stack has drifted from its template,
+// reusing the last result for up to driftCacheTTL instead of kicking off a
+// new DetectStackDrift run on every call. Callers should refuse to launch
+// scanner jobs (or emit an event) when this returns true, since
+// SubnetID/SecurityGroupID/AmiID may no longer point at what the stack
+// believes they do.
+func (m *Manager) DetectDrift(ctx context.Context) (bool, error) {
+	m.driftMu.Lock()
+	defer m.driftMu.Unlock()
+
+	if m.driftCache != nil && time.Now().Before(m.driftCache.expiresAt) {
+		return m.driftCache.drifted, nil
+	}
+
+	drifted, err := m.detectDrift(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	m.driftCache = &driftCacheEntry{drifted: drifted, expiresAt: time.Now().Add(driftCacheTTL)}
+
+	return drifted, nil
+}
+
+// detectDrift kicks off a drift detection run against the stack and blocks
+// until it completes, returning true if any resource has drifted from the
+// template.
+func (m *Manager) detectDrift(ctx context.Context) (bool, error) {
+	detectOut, err := m.cfnClient.DetectStackDrift(ctx, &cloudformation.DetectStackDriftInput{
+		StackName: &m.stackName,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to start drift detection on stack %s: %w", m.stackName, err)
+	}
+
+	deadline := time.Now().Add(driftDetectionTimeout)
+	for {
+		statusOut, err := m.cfnClient.DescribeStackDriftDetectionStatus(ctx, &cloudformation.DescribeStackDriftDetectionStatusInput{
+			StackDriftDetectionId: detectOut.StackDriftDetectionId,
+		})
+		if err != nil {
+			return false, fmt.Errorf("failed to get drift detection status for stack %s: %w", m.stackName, err)
+		}
+
+		switch statusOut.DetectionStatus {
+		case cfntypes.StackDriftDetectionStatusDetectionComplete:
+			return statusOut.StackDriftStatus == cfntypes.StackDriftStatusDrifted, nil
+		case cfntypes.StackDriftDetectionStatusDetectionFailed:
+			return false, fmt.Errorf("drift detection failed for stack %s: %s", m.stackName, aws.ToString(statusOut.DetectionStatusReason))
+		}
+
+		if time.Now().After(deadline) {
+			return false, fmt.Errorf("timed out waiting for drift detection on stack %s", m.stackName)
+		}
+
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-time.After(driftDetectionPollInterval):
+		}
+	}
+}
+
+// Teardown deletes the stack and everything it owns.
+func (m *Manager) Teardown(ctx context.Context) error {
+	_, err := m.cfnClient.DeleteStack(ctx, &cloudformation.DeleteStackInput{
+		StackName: &m.stackName,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete stack %s: %w", m.stackName, err)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+// Copyright © 2022 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cfn
+
+import (
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	cfntypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+// requiredCapabilities is what the scanner infrastructure template needs in
+// order to create the IAM instance profile it declares.
+var requiredCapabilities = []cfntypes.Capability{
+	cfntypes.CapabilityCapabilityNamedIam,
+}
+
+func buildParameters(parameters map[string]string) []cfntypes.Parameter {
+	ret := make([]cfntypes.Parameter, 0, len(parameters))
+	for key, value := range parameters {
+		key, value := key, value
+		ret = append(ret, cfntypes.Parameter{
+			ParameterKey:   &key,
+			ParameterValue: &value,
+		})
+	}
+	return ret
+}
+
+func buildCreateStackInput(stackName, templateBody string, parameters map[string]string) *cloudformation.CreateStackInput {
+	return &cloudformation.CreateStackInput{
+		StackName:    &stackName,
+		TemplateBody: &templateBody,
+		Parameters:   buildParameters(parameters),
+		Capabilities: requiredCapabilities,
+	}
+}
+
+func buildUpdateStackInput(stackName, templateBody string, parameters map[string]string) *cloudformation.UpdateStackInput {
+	return &cloudformation.UpdateStackInput{
+		StackName:    &stackName,
+		TemplateBody: &templateBody,
+		Parameters:   buildParameters(parameters),
+		Capabilities: requiredCapabilities,
+	}
+}
@@ -18,25 +18,40 @@ package aws
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/servicequotas"
+	"github.com/aws/smithy-go"
 	log "github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/semaphore"
 
 	"github.com/openclarity/vmclarity/api/models"
 	"github.com/openclarity/vmclarity/runtime_scan/pkg/cloudinit"
 	"github.com/openclarity/vmclarity/runtime_scan/pkg/config/aws"
 	"github.com/openclarity/vmclarity/runtime_scan/pkg/provider"
+	"github.com/openclarity/vmclarity/runtime_scan/pkg/provider/aws/cfn"
 	"github.com/openclarity/vmclarity/runtime_scan/pkg/types"
 	"github.com/openclarity/vmclarity/runtime_scan/pkg/utils"
 )
 
 type Client struct {
-	ec2Client *ec2.Client
-	awsConfig *aws.Config
+	ec2Client             *ec2.Client
+	quotaClient           quotaClient
+	awsConfig             *aws.Config
+	quotaCache            *quotaCache
+	instanceTypeVCPUCache *instanceTypeVCPUCache
+
+	// infra manages the CloudFormation-provisioned scanner networking
+	// stack. Only set when awsConfig.StackName is non-empty.
+	infra *cfn.Manager
 }
 
 var (
@@ -54,7 +69,9 @@ var (
 
 func Create(ctx context.Context, config *aws.Config) (*Client, error) {
 	awsClient := Client{
-		awsConfig: config,
+		awsConfig:             config,
+		quotaCache:            newQuotaCache(config.QuotaCacheTTL),
+		instanceTypeVCPUCache: newInstanceTypeVCPUCache(config.QuotaCacheTTL),
 	}
 
 	cfg, err := awsconfig.LoadDefaultConfig(ctx)
@@ -64,12 +81,50 @@ func Create(ctx context.Context, config *aws.Config) (*Client, error) {
 
 	// nolint:contextcheck
 	awsClient.ec2Client = ec2.NewFromConfig(cfg)
+	awsClient.quotaClient = servicequotas.NewFromConfig(cfg)
+
+	if config.StackName != "" {
+		awsClient.infra = cfn.New(cloudformation.NewFromConfig(cfg), config.StackName)
+
+		if err := awsClient.infra.EnsureDefaultStack(ctx); err != nil {
+			return nil, fmt.Errorf("failed to provision scanner infrastructure stack %s: %w", config.StackName, err)
+		}
+
+		outputs, err := awsClient.infra.Outputs(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve scanner infrastructure stack %s outputs: %w", config.StackName, err)
+		}
+		config.SubnetID = outputs.SubnetID
+		config.SecurityGroupID = outputs.SecurityGroupID
+		config.AmiID = outputs.AmiID
+	}
 
 	return &awsClient, nil
 }
 
+// TeardownInfrastructure deletes the CloudFormation-managed scanner
+// networking stack. It's a no-op when the provider wasn't configured with
+// awsConfig.StackName.
+func (c *Client) TeardownInfrastructure(ctx context.Context) error {
+	if c.infra == nil {
+		return nil
+	}
+	return c.infra.Teardown(ctx)
+}
+
+// defaultMaxConcurrentRegions/VPCs bound fan-out when the operator hasn't
+// set aws.Config.MaxConcurrentRegions/MaxConcurrentVPCs, so Discover never
+// opens an unbounded number of DescribeInstances calls against an account
+// with many regions and VPCs.
+const (
+	defaultMaxConcurrentRegions = 10
+	defaultMaxConcurrentVPCs    = 10
+)
+
+// scannerInstanceType is the instance type used to run scanner jobs.
+const scannerInstanceType = ec2types.InstanceTypeT2Large
+
 func (c *Client) Discover(ctx context.Context, scanScope *models.ScanScopeType) ([]types.Instance, error) {
-	var ret []types.Instance
 	var filters []ec2types.Filter
 
 	awsScanScope, err := scanScope.AsAwsScanScope()
@@ -89,28 +144,84 @@ func (c *Client) Discover(ctx context.Context, scanScope *models.ScanScopeType)
 	filters = append(filters, createInclusionTagsFilters(scope.TagSelector)...)
 	filters = append(filters, createInstanceStateFilters(scope.ScanStopped)...)
 
+	maxConcurrentRegions := int64(c.awsConfig.MaxConcurrentRegions)
+	if maxConcurrentRegions <= 0 {
+		maxConcurrentRegions = defaultMaxConcurrentRegions
+	}
+	maxConcurrentVPCs := int64(c.awsConfig.MaxConcurrentVPCs)
+	if maxConcurrentVPCs <= 0 {
+		maxConcurrentVPCs = defaultMaxConcurrentVPCs
+	}
+	regionSem := semaphore.NewWeighted(maxConcurrentRegions)
+	vpcSem := semaphore.NewWeighted(maxConcurrentVPCs)
+
+	// resultsCh is fed by every region/VPC goroutine and drained by the
+	// collector loop below, so each GetInstances call streams its pages
+	// instead of buffering a per-call slice first. Discover itself still
+	// returns the collected slice once every producer is done.
+	resultsCh := make(chan types.Instance)
+
+	eg, egCtx := errgroup.WithContext(ctx)
+
 	for _, region := range regions {
-		// if no vpcs, that mean that we don't need any vpc filters
+		region := region
+
 		if len(region.vpcs) == 0 {
-			instances, err := c.GetInstances(ctx, filters, scope.ExcludeTags, region.name)
-			if err != nil {
-				return nil, fmt.Errorf("failed to get instances: %v", err)
-			}
-			ret = append(ret, instances...)
+			eg.Go(func() error {
+				if err := regionSem.Acquire(egCtx, 1); err != nil {
+					return fmt.Errorf("failed to acquire region semaphore: %w", err)
+				}
+				defer regionSem.Release(1)
+
+				if err := c.GetInstances(egCtx, filters, scope.ExcludeTags, scope.ExcludeInstanceIDs, region.name, resultsCh); err != nil {
+					return fmt.Errorf("failed to get instances from region %s: %w", region.name, err)
+				}
+				return nil
+			})
 			continue
 		}
 
 		// need to do a per vpc call for DescribeInstances
 		for _, vpc := range region.vpcs {
-			vpcFilters := append(filters, createVPCFilters(vpc)...)
-
-			instances, err := c.GetInstances(ctx, vpcFilters, scope.ExcludeTags, region.name)
-			if err != nil {
-				return nil, fmt.Errorf("failed to get instances: %v", err)
+			if isExcludedVPC(vpc.id, scope.ExcludeVPCs) {
+				continue
 			}
-			ret = append(ret, instances...)
+			region, vpc := region, vpc
+
+			eg.Go(func() error {
+				if err := vpcSem.Acquire(egCtx, 1); err != nil {
+					return fmt.Errorf("failed to acquire vpc semaphore: %w", err)
+				}
+				defer vpcSem.Release(1)
+
+				vpcFilters := append(append([]ec2types.Filter{}, filters...), createVPCFilters(vpc)...)
+
+				if err := c.GetInstances(egCtx, vpcFilters, scope.ExcludeTags, scope.ExcludeInstanceIDs, region.name, resultsCh); err != nil {
+					return fmt.Errorf("failed to get instances from vpc %s in region %s: %w", vpc.id, region.name, err)
+				}
+				return nil
+			})
 		}
 	}
+
+	// Close resultsCh once every producer is done (success or not) so the
+	// collector loop below terminates; the first non-context-cancel error
+	// from any goroutine is returned by eg.Wait().
+	var egErr error
+	go func() {
+		egErr = eg.Wait()
+		close(resultsCh)
+	}()
+
+	var ret []types.Instance
+	for instance := range resultsCh {
+		ret = append(ret, instance)
+	}
+
+	if egErr != nil {
+		return nil, fmt.Errorf("failed to discover instances: %w", egErr)
+	}
+
 	return ret, nil
 }
 
@@ -121,12 +232,22 @@ func convertScope(scope *models.AwsScanScope) *ScanScope {
 	}
 
 	return &ScanScope{
-		AllRegions:  allRegions,
-		Regions:     convertRegions(scope.Regions),
-		ScanStopped: convertBool(scope.ShouldScanStoppedInstances),
-		TagSelector: convertTags(scope.InstanceTagSelector),
-		ExcludeTags: convertTags(scope.InstanceTagExclusion),
+		AllRegions:         allRegions,
+		Regions:            convertRegions(scope.Regions),
+		ScanStopped:        convertBool(scope.ShouldScanStoppedInstances),
+		TagSelector:        convertTags(scope.InstanceTagSelector),
+		ExcludeTags:        convertTags(scope.InstanceTagExclusion),
+		ExcludeRegions:     convertStrings(scope.ExcludeRegions),
+		ExcludeVPCs:        convertStrings(scope.ExcludeVPCs),
+		ExcludeInstanceIDs: convertStrings(scope.ExcludeInstanceIDs),
+	}
+}
+
+func convertStrings(strs *[]string) []string {
+	if strs == nil {
+		return nil
 	}
+	return *strs
 }
 
 func convertTags(tags *[]models.Tag) []Tag {
@@ -194,6 +315,20 @@ func convertBool(all *bool) bool {
 }
 
 func (c *Client) RunScanningJob(ctx context.Context, region, id string, config provider.ScanningJobConfig) (types.Instance, error) {
+	if c.infra != nil {
+		drifted, err := c.infra.DetectDrift(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check scanner infrastructure stack for drift: %w", err)
+		}
+		if drifted {
+			return nil, fmt.Errorf("refusing to launch scanner job: scanner infrastructure stack %s has drifted from its template", c.awsConfig.StackName)
+		}
+	}
+
+	if err := c.checkCapacity(ctx, region, string(scannerInstanceType)); err != nil {
+		return nil, fmt.Errorf("failed pre-flight capacity check: %w", err)
+	}
+
 	cloudInitData := cloudinit.Data{
 		ScannerCLIConfig: config.ScannerCLIConfig,
 		ScannerImage:     config.ScannerImage,
@@ -212,7 +347,7 @@ func (c *Client) RunScanningJob(ctx context.Context, region, id string, config p
 		MaxCount:     utils.Int32Ptr(1),
 		MinCount:     utils.Int32Ptr(1),
 		ImageId:      &c.awsConfig.AmiID,
-		InstanceType: ec2types.InstanceTypeT2Large, // TODO need to decide instance type
+		InstanceType: scannerInstanceType, // TODO need to decide instance type
 		TagSpecifications: []ec2types.TagSpecification{
 			{
 				ResourceType: ec2types.ResourceTypeInstance,
@@ -242,19 +377,63 @@ func (c *Client) RunScanningJob(ctx context.Context, region, id string, config p
 		runInstancesInput.KeyName = &config.KeyPairName
 	}
 
+	useSpot := c.awsConfig.UseSpot
+	if config.UseSpot != nil {
+		useSpot = *config.UseSpot
+	}
+
+	isSpot := false
+	if useSpot {
+		runInstancesInput.InstanceMarketOptions = c.spotMarketOptions(config)
+		isSpot = true
+	}
+
 	out, err := c.ec2Client.RunInstances(ctx, runInstancesInput, func(options *ec2.Options) {
 		options.Region = region
 	})
+	if isSpot && isSpotCapacityError(err) {
+		log.Warningf("spot capacity unavailable for scanner job %s (%v), falling back to on-demand", id, err)
+		runInstancesInput.InstanceMarketOptions = nil
+		isSpot = false
+		out, err = c.ec2Client.RunInstances(ctx, runInstancesInput, func(options *ec2.Options) {
+			options.Region = region
+		})
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to run instances: %v", err)
 	}
 
-	return &InstanceImpl{
+	instanceID := *out.Instances[0].InstanceId
+
+	if isSpot {
+		// Spot request/instance tagging via RunInstances' TagSpecifications
+		// is asynchronous on AWS, so retry CreateTags until it lands.
+		if err := c.tagSpotInstance(ctx, region, instanceID, instanceTags); err != nil {
+			log.Errorf("failed to tag spot scanner instance %s, continuing untagged: %v", instanceID, err)
+		}
+	}
+
+	instance := &InstanceImpl{
 		ec2Client:        c.ec2Client,
-		id:               *out.Instances[0].InstanceId,
+		id:               instanceID,
 		region:           region,
 		availabilityZone: *out.Instances[0].Placement.AvailabilityZone,
-	}, nil
+		isSpot:           isSpot,
+	}
+	if isSpot && out.Instances[0].SpotInstanceRequestId != nil {
+		instance.spotRequestID = *out.Instances[0].SpotInstanceRequestId
+
+		watchCtx, cancel := context.WithCancel(context.Background())
+		instance.stopWatch = cancel
+		go instance.WatchForSpotInterruption(watchCtx, func() {
+			log.Warningf("spot instance %s for scanner job %s was marked for reclamation", instanceID, id)
+			if config.OnSpotInterrupted != nil {
+				config.OnSpotInterrupted()
+			}
+		})
+	}
+
+	return instance, nil
 }
 
 func createInstanceTags(id string) []ec2types.Tag {
@@ -270,9 +449,92 @@ func createInstanceTags(id string) []ec2types.Tag {
 	return ret
 }
 
-func (c *Client) GetInstances(ctx context.Context, filters []ec2types.Filter, excludeTags []Tag, regionID string) ([]types.Instance, error) {
-	ret := make([]types.Instance, 0)
+const (
+	defaultSpotInterruptionBehavior = ec2types.InstanceInterruptionBehaviorTerminate
+
+	// spotTagRetryAttempts/spotTagRetryBaseDelay bound how long we retry
+	// CreateTags against a freshly launched spot instance, since spot
+	// request tagging can lag RunInstances by a few seconds.
+	spotTagRetryAttempts  = 5
+	spotTagRetryBaseDelay = 2 * time.Second
+)
+
+// spotMarketOptions builds the InstanceMarketOptions needed to request a
+// one-time spot instance, applying job-level overrides on top of the
+// provider-wide spot defaults.
+func (c *Client) spotMarketOptions(config provider.ScanningJobConfig) *ec2types.InstanceMarketOptionsRequest {
+	maxPrice := c.awsConfig.MaxSpotPrice
+	if config.MaxSpotPrice != "" {
+		maxPrice = config.MaxSpotPrice
+	}
+
+	interruptionBehavior := c.awsConfig.SpotInterruptionBehavior
+	if config.SpotInterruptionBehavior != "" {
+		interruptionBehavior = config.SpotInterruptionBehavior
+	}
+	behavior := defaultSpotInterruptionBehavior
+	if interruptionBehavior != "" {
+		behavior = ec2types.InstanceInterruptionBehavior(interruptionBehavior)
+	}
+
+	spotOptions := &ec2types.SpotMarketOptions{
+		SpotInstanceType:             ec2types.SpotInstanceTypeOneTime,
+		InstanceInterruptionBehavior: behavior,
+	}
+	if maxPrice != "" {
+		spotOptions.MaxPrice = &maxPrice
+	}
+
+	return &ec2types.InstanceMarketOptionsRequest{
+		MarketType:  ec2types.MarketTypeSpot,
+		SpotOptions: spotOptions,
+	}
+}
+
+// isSpotCapacityError reports whether err is an AWS error indicating spot
+// capacity isn't available, so the caller can fall back to on-demand.
+func isSpotCapacityError(err error) bool {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.ErrorCode() {
+	case "InsufficientInstanceCapacity", "SpotMaxPriceTooLow":
+		return true
+	default:
+		return false
+	}
+}
+
+// tagSpotInstance retries CreateTags against a newly launched spot instance,
+// since tagging a spot request/instance via RunInstances' TagSpecifications
+// is asynchronous on AWS and can briefly 404 right after launch.
+func (c *Client) tagSpotInstance(ctx context.Context, region, instanceID string, tags []ec2types.Tag) error {
+	var err error
+	for attempt := 0; attempt < spotTagRetryAttempts; attempt++ {
+		_, err = c.ec2Client.CreateTags(ctx, &ec2.CreateTagsInput{
+			Resources: []string{instanceID},
+			Tags:      tags,
+		}, func(options *ec2.Options) {
+			options.Region = region
+		})
+		if err == nil {
+			return nil
+		}
 
+		select {
+		case <-time.After(spotTagRetryBaseDelay * time.Duration(attempt+1)):
+		case <-ctx.Done():
+			return fmt.Errorf("context cancelled while retrying spot instance tagging: %w", ctx.Err())
+		}
+	}
+	return fmt.Errorf("failed to tag spot instance %s after %d attempts: %w", instanceID, spotTagRetryAttempts, err)
+}
+
+// GetInstances streams every types.Instance matching filters/excludeTags in
+// regionID onto resultsCh as each DescribeInstances page arrives, instead of
+// accumulating the whole region's result set in memory before returning.
+func (c *Client) GetInstances(ctx context.Context, filters []ec2types.Filter, excludeTags []Tag, excludeInstanceIDs []string, regionID string, resultsCh chan<- types.Instance) error {
 	out, err := c.ec2Client.DescribeInstances(ctx, &ec2.DescribeInstancesInput{
 		Filters:    filters,
 		MaxResults: utils.Int32Ptr(maxResults), // TODO what will be a good number?
@@ -280,12 +542,13 @@ func (c *Client) GetInstances(ctx context.Context, filters []ec2types.Filter, ex
 		options.Region = regionID
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to describe instances: %v", err)
+		return fmt.Errorf("failed to describe instances: %v", err)
+	}
+	if err := sendInstances(ctx, resultsCh, c.getInstancesFromDescribeInstancesOutput(out, excludeTags, excludeInstanceIDs, regionID)); err != nil {
+		return err
 	}
-	ret = append(ret, c.getInstancesFromDescribeInstancesOutput(out, excludeTags, regionID)...)
 
 	// use pagination
-	// TODO we can make it better by not saving all results in memory. See https://github.com/openclarity/vmclarity/pull/3#discussion_r1021656861
 	for out.NextToken != nil {
 		out, err = c.ec2Client.DescribeInstances(ctx, &ec2.DescribeInstancesInput{
 			Filters:    filters,
@@ -295,12 +558,28 @@ func (c *Client) GetInstances(ctx context.Context, filters []ec2types.Filter, ex
 			options.Region = regionID
 		})
 		if err != nil {
-			return nil, fmt.Errorf("failed to describe instances: %v", err)
+			return fmt.Errorf("failed to describe instances: %v", err)
+		}
+		if err := sendInstances(ctx, resultsCh, c.getInstancesFromDescribeInstancesOutput(out, excludeTags, excludeInstanceIDs, regionID)); err != nil {
+			return err
 		}
-		ret = append(ret, c.getInstancesFromDescribeInstancesOutput(out, excludeTags, regionID)...)
 	}
 
-	return ret, nil
+	return nil
+}
+
+// sendInstances pushes each instance onto resultsCh, bailing out early if ctx
+// is cancelled so a slow consumer or a sibling goroutine's error doesn't
+// leave producers blocked forever.
+func sendInstances(ctx context.Context, resultsCh chan<- types.Instance, instances []types.Instance) error {
+	for _, instance := range instances {
+		select {
+		case resultsCh <- instance:
+		case <-ctx.Done():
+			return fmt.Errorf("context cancelled while streaming instances: %w", ctx.Err())
+		}
+	}
+	return nil
 }
 
 func getInstanceState(result *ec2.DescribeInstancesOutput, instanceID string) ec2types.InstanceStateName {
@@ -316,7 +595,7 @@ func getInstanceState(result *ec2.DescribeInstancesOutput, instanceID string) ec
 	return ec2types.InstanceStateNamePending
 }
 
-func (c *Client) getInstancesFromDescribeInstancesOutput(result *ec2.DescribeInstancesOutput, excludeTags []Tag, regionID string) []types.Instance {
+func (c *Client) getInstancesFromDescribeInstancesOutput(result *ec2.DescribeInstancesOutput, excludeTags []Tag, excludeInstanceIDs []string, regionID string) []types.Instance {
 	var ret []types.Instance
 
 	for _, reservation := range result.Reservations {
@@ -324,6 +603,9 @@ func (c *Client) getInstancesFromDescribeInstancesOutput(result *ec2.DescribeIns
 			if hasExcludeTags(excludeTags, instance.Tags) {
 				continue
 			}
+			if instance.InstanceId != nil && matchesAny(*instance.InstanceId, excludeInstanceIDs) {
+				continue
+			}
 			ret = append(ret, &InstanceImpl{
 				ec2Client: c.ec2Client,
 				id:        *instance.InstanceId,
@@ -402,11 +684,59 @@ func createInclusionTagsFilters(tags []Tag) []ec2types.Filter {
 }
 
 func (c *Client) getRegionsToScan(ctx context.Context, scope *ScanScope) ([]Region, error) {
+	var regions []Region
+	var err error
+
 	if scope.AllRegions {
-		return c.ListAllRegions(ctx)
+		regions, err = c.ListAllRegions(ctx)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		regions = scope.Regions
 	}
 
-	return scope.Regions, nil
+	return filterExcludedRegions(regions, scope.ExcludeRegions), nil
+}
+
+// filterExcludedRegions drops any region whose name matches one of the
+// exclude patterns. Patterns support a single trailing "*" glob (e.g.
+// "us-gov-*", "cn-*") in addition to exact matches.
+func filterExcludedRegions(regions []Region, excludeRegions []string) []Region {
+	if len(excludeRegions) == 0 {
+		return regions
+	}
+
+	ret := make([]Region, 0, len(regions))
+	for _, region := range regions {
+		if !matchesAny(region.name, excludeRegions) {
+			ret = append(ret, region)
+		}
+	}
+	return ret
+}
+
+// matchesAny reports whether s matches any of patterns, where a pattern
+// ending in "*" matches as a prefix and any other pattern must match
+// exactly.
+func matchesAny(s string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if strings.HasSuffix(pattern, "*") {
+			if strings.HasPrefix(s, strings.TrimSuffix(pattern, "*")) {
+				return true
+			}
+			continue
+		}
+		if s == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// isExcludedVPC reports whether vpcID is in excludeVPCs.
+func isExcludedVPC(vpcID string, excludeVPCs []string) bool {
+	return matchesAny(vpcID, excludeVPCs)
 }
 
 func (c *Client) ListAllRegions(ctx context.Context) ([]Region, error) {
@@ -0,0 +1,219 @@
+// Copyright © 2022 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aws
+
+import (
+	"testing"
+
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+func TestMatchesAny(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        string
+		patterns []string
+		want     bool
+	}{
+		{
+			name:     "no patterns",
+			s:        "us-east-1",
+			patterns: nil,
+			want:     false,
+		},
+		{
+			name:     "exact match",
+			s:        "us-east-1",
+			patterns: []string{"eu-west-1", "us-east-1"},
+			want:     true,
+		},
+		{
+			name:     "no match",
+			s:        "us-east-1",
+			patterns: []string{"eu-west-1", "ap-south-1"},
+			want:     false,
+		},
+		{
+			name:     "glob prefix match",
+			s:        "us-gov-west-1",
+			patterns: []string{"us-gov-*"},
+			want:     true,
+		},
+		{
+			name:     "glob prefix does not match unrelated string",
+			s:        "us-east-1",
+			patterns: []string{"us-gov-*"},
+			want:     false,
+		},
+		{
+			name:     "glob does not match as a substring, only a prefix",
+			s:        "x-us-gov-1",
+			patterns: []string{"us-gov-*"},
+			want:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchesAny(tt.s, tt.patterns); got != tt.want {
+				t.Errorf("matchesAny(%q, %v) = %v, want %v", tt.s, tt.patterns, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterExcludedRegions(t *testing.T) {
+	regions := []Region{{name: "us-east-1"}, {name: "us-gov-west-1"}, {name: "cn-north-1"}, {name: "eu-west-1"}}
+
+	tests := []struct {
+		name           string
+		excludeRegions []string
+		want           []string
+	}{
+		{
+			name:           "no exclusions returns all regions",
+			excludeRegions: nil,
+			want:           []string{"us-east-1", "us-gov-west-1", "cn-north-1", "eu-west-1"},
+		},
+		{
+			name:           "exact exclusion drops only that region",
+			excludeRegions: []string{"eu-west-1"},
+			want:           []string{"us-east-1", "us-gov-west-1", "cn-north-1"},
+		},
+		{
+			name:           "glob exclusion drops every matching region",
+			excludeRegions: []string{"us-gov-*", "cn-*"},
+			want:           []string{"us-east-1", "eu-west-1"},
+		},
+		{
+			name:           "exclusion matching nothing leaves all regions",
+			excludeRegions: []string{"ap-south-1"},
+			want:           []string{"us-east-1", "us-gov-west-1", "cn-north-1", "eu-west-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := filterExcludedRegions(regions, tt.excludeRegions)
+
+			gotNames := make([]string, len(got))
+			for i, region := range got {
+				gotNames[i] = region.name
+			}
+
+			if len(gotNames) != len(tt.want) {
+				t.Fatalf("filterExcludedRegions() = %v, want %v", gotNames, tt.want)
+			}
+			for i := range gotNames {
+				if gotNames[i] != tt.want[i] {
+					t.Fatalf("filterExcludedRegions() = %v, want %v", gotNames, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func strPtr(s string) *string { return &s }
+
+func TestHasExcludeTags(t *testing.T) {
+	tests := []struct {
+		name         string
+		excludeTags  []Tag
+		instanceTags []ec2types.Tag
+		want         bool
+	}{
+		{
+			name:         "no exclude tags never excludes",
+			excludeTags:  nil,
+			instanceTags: []ec2types.Tag{{Key: strPtr("env"), Value: strPtr("prod")}},
+			want:         false,
+		},
+		{
+			name:         "no instance tags never excludes",
+			excludeTags:  []Tag{{Key: "env", Val: "prod"}},
+			instanceTags: nil,
+			want:         false,
+		},
+		{
+			name:        "single exclude tag matches",
+			excludeTags: []Tag{{Key: "env", Val: "prod"}},
+			instanceTags: []ec2types.Tag{
+				{Key: strPtr("env"), Value: strPtr("prod")},
+			},
+			want: true,
+		},
+		{
+			name:        "single exclude tag with different value does not match",
+			excludeTags: []Tag{{Key: "env", Val: "prod"}},
+			instanceTags: []ec2types.Tag{
+				{Key: strPtr("env"), Value: strPtr("staging")},
+			},
+			want: false,
+		},
+		{
+			name: "AND logic requires every exclude tag to match",
+			excludeTags: []Tag{
+				{Key: "env", Val: "prod"},
+				{Key: "team", Val: "platform"},
+			},
+			instanceTags: []ec2types.Tag{
+				{Key: strPtr("env"), Value: strPtr("prod")},
+				{Key: strPtr("team"), Value: strPtr("platform")},
+			},
+			want: true,
+		},
+		{
+			name: "AND logic excludes nothing when only some exclude tags match",
+			excludeTags: []Tag{
+				{Key: "env", Val: "prod"},
+				{Key: "team", Val: "platform"},
+			},
+			instanceTags: []ec2types.Tag{
+				{Key: strPtr("env"), Value: strPtr("prod")},
+				{Key: strPtr("team"), Value: strPtr("other")},
+			},
+			want: false,
+		},
+		{
+			name: "AND logic excludes nothing when an exclude tag is missing entirely",
+			excludeTags: []Tag{
+				{Key: "env", Val: "prod"},
+				{Key: "team", Val: "platform"},
+			},
+			instanceTags: []ec2types.Tag{
+				{Key: strPtr("env"), Value: strPtr("prod")},
+			},
+			want: false,
+		},
+		{
+			name:        "extra instance tags beyond the exclude set don't prevent exclusion",
+			excludeTags: []Tag{{Key: "env", Val: "prod"}},
+			instanceTags: []ec2types.Tag{
+				{Key: strPtr("env"), Value: strPtr("prod")},
+				{Key: strPtr("team"), Value: strPtr("platform")},
+			},
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hasExcludeTags(tt.excludeTags, tt.instanceTags); got != tt.want {
+				t.Errorf("hasExcludeTags(%v, %v) = %v, want %v", tt.excludeTags, tt.instanceTags, got, tt.want)
+			}
+		})
+	}
+}
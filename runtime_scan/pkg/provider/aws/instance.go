@@ -0,0 +1,190 @@
+// Copyright © 2022 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aws
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openclarity/vmclarity/runtime_scan/pkg/types"
+	"github.com/openclarity/vmclarity/runtime_scan/pkg/utils"
+)
+
+const (
+	instanceReadyTimeout = 10 * time.Minute
+
+	// spotInterruptionPollInterval is how often WatchForSpotInterruption
+	// polls DescribeSpotInstanceRequests for an interruption notice. AWS
+	// gives a 2-minute warning before reclaiming the instance, so this
+	// needs to be well under that.
+	spotInterruptionPollInterval = 15 * time.Second
+)
+
+// interruptedSpotStatusCodes are the DescribeSpotInstanceRequests status
+// codes AWS sets once it has decided to reclaim a spot instance.
+// See: https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/spot-interruptions.html
+var interruptedSpotStatusCodes = map[string]bool{
+	"marked-for-termination":          true,
+	"marked-for-stop":                 true,
+	"marked-for-hibernation":          true,
+	"instance-terminated-by-price":    true,
+	"instance-terminated-by-service":  true,
+	"instance-terminated-no-capacity": true,
+}
+
+// InstanceImpl is the AWS implementation of types.Instance, backed by an EC2
+// instance.
+type InstanceImpl struct {
+	ec2Client        *ec2.Client
+	id               string
+	region           string
+	availabilityZone string
+
+	// isSpot and spotRequestID are only set for instances launched via
+	// the spot market; they gate WatchForSpotInterruption.
+	isSpot        bool
+	spotRequestID string
+
+	// stopWatch cancels the WatchForSpotInterruption goroutine RunScanningJob
+	// started for this instance, if any. Delete calls it so the goroutine
+	// doesn't keep polling after the instance it's watching is gone.
+	stopWatch context.CancelFunc
+}
+
+// WatchForSpotInterruption polls for a spot interruption notice on this
+// instance's spot request and invokes onInterrupted once AWS has marked the
+// instance for reclamation, so the caller can mark the in-flight scan result
+// as needing retry before the ~2 minute warning expires. It is a no-op for
+// on-demand instances. The caller is expected to run this in a goroutine and
+// cancel ctx to stop watching (e.g. once the scan completes).
+func (i *InstanceImpl) WatchForSpotInterruption(ctx context.Context, onInterrupted func()) {
+	if !i.isSpot || i.spotRequestID == "" {
+		return
+	}
+
+	ticker := time.NewTicker(spotInterruptionPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			interrupted, err := i.spotInterruptionNoticeReceived(ctx)
+			if err != nil {
+				log.Warningf("failed to check spot interruption status for instance %s: %v", i.id, err)
+				continue
+			}
+			if interrupted {
+				onInterrupted()
+				return
+			}
+		}
+	}
+}
+
+func (i *InstanceImpl) spotInterruptionNoticeReceived(ctx context.Context) (bool, error) {
+	out, err := i.ec2Client.DescribeSpotInstanceRequests(ctx, &ec2.DescribeSpotInstanceRequestsInput{
+		SpotInstanceRequestIds: []string{i.spotRequestID},
+	}, func(options *ec2.Options) {
+		options.Region = i.region
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to describe spot instance request %s: %w", i.spotRequestID, err)
+	}
+
+	for _, req := range out.SpotInstanceRequests {
+		if req.Status == nil || req.Status.Code == nil {
+			continue
+		}
+		if interruptedSpotStatusCodes[*req.Status.Code] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (i *InstanceImpl) GetID() string {
+	return i.id
+}
+
+func (i *InstanceImpl) GetRegion() string {
+	return i.region
+}
+
+func (i *InstanceImpl) WaitForReady(ctx context.Context) error {
+	waiter := ec2.NewInstanceRunningWaiter(i.ec2Client)
+	err := waiter.Wait(ctx, &ec2.DescribeInstancesInput{
+		InstanceIds: []string{i.id},
+	}, instanceReadyTimeout, func(options *ec2.InstanceRunningWaiterOptions) {
+		options.MinDelay = 5 * time.Second
+	})
+	if err != nil {
+		return fmt.Errorf("failed to wait for instance %s to be running: %v", i.id, err)
+	}
+	return nil
+}
+
+func (i *InstanceImpl) GetVolumes(ctx context.Context) ([]types.Volume, error) {
+	out, err := i.ec2Client.DescribeVolumes(ctx, &ec2.DescribeVolumesInput{
+		Filters: []ec2types.Filter{
+			{
+				Name:   utils.StringPtr("attachment.instance-id"),
+				Values: []string{i.id},
+			},
+		},
+	}, func(options *ec2.Options) {
+		options.Region = i.region
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe volumes for instance %s: %w", i.id, err)
+	}
+
+	ret := make([]types.Volume, 0, len(out.Volumes))
+	for _, volume := range out.Volumes {
+		if volume.VolumeId == nil || volume.Size == nil {
+			continue
+		}
+		ret = append(ret, types.Volume{
+			ID:         *volume.VolumeId,
+			SizeGB:     *volume.Size,
+			VolumeType: string(volume.VolumeType),
+		})
+	}
+
+	return ret, nil
+}
+
+func (i *InstanceImpl) Delete(ctx context.Context) error {
+	if i.stopWatch != nil {
+		i.stopWatch()
+	}
+
+	_, err := i.ec2Client.TerminateInstances(ctx, &ec2.TerminateInstancesInput{
+		InstanceIds: []string{i.id},
+	}, func(options *ec2.Options) {
+		options.Region = i.region
+	})
+	if err != nil {
+		return fmt.Errorf("failed to terminate instance %s: %v", i.id, err)
+	}
+	return nil
+}
@@ -0,0 +1,289 @@
+// Copyright © 2022 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aws
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/servicequotas"
+
+	"github.com/openclarity/vmclarity/runtime_scan/pkg/utils"
+)
+
+// standardInstancesQuotaCode is L-1216C47A, "Running On-Demand Standard
+// (A, C, D, H, I, M, R, T, Z) instances", expressed in vCPUs.
+const standardInstancesQuotaCode = "L-1216C47A"
+
+// ec2QuotaServiceCode is the Service Quotas service code for EC2.
+const ec2QuotaServiceCode = "ec2"
+
+// defaultQuotaCacheTTL bounds how long a quota or instance-type vCPU lookup
+// is reused before we go back to the API, so checkCapacity doesn't hammer
+// Service Quotas/EC2 on every scan. aws.Config.QuotaCacheTTL overrides it.
+const defaultQuotaCacheTTL = 1 * time.Hour
+
+// quotaClient is the subset of the Service Quotas SDK client that
+// checkCapacity needs, so tests can provide a fake.
+type quotaClient interface {
+	GetServiceQuota(ctx context.Context, params *servicequotas.GetServiceQuotaInput, optFns ...func(*servicequotas.Options)) (*servicequotas.GetServiceQuotaOutput, error)
+}
+
+// ErrRegionQuotaExhausted is returned by checkCapacity when launching the
+// requested instance type in Region would exceed the account's EC2 quota, so
+// callers can spread scanner jobs across regions instead of eating an opaque
+// VcpuLimitExceeded error from RunInstances.
+type ErrRegionQuotaExhausted struct {
+	Region    string
+	Requested float64
+	Available float64
+}
+
+func (e *ErrRegionQuotaExhausted) Error() string {
+	return fmt.Sprintf("region %s has insufficient EC2 quota headroom: requested %.1f vCPUs, %.1f available",
+		e.Region, e.Requested, e.Available)
+}
+
+type quotaCacheKey struct {
+	region    string
+	quotaCode string
+}
+
+type quotaCacheEntry struct {
+	value     float64
+	expiresAt time.Time
+}
+
+// quotaCache memoizes Service Quotas lookups per (region, quotaCode) for ttl.
+type quotaCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[quotaCacheKey]quotaCacheEntry
+}
+
+func newQuotaCache(ttl time.Duration) *quotaCache {
+	if ttl <= 0 {
+		ttl = defaultQuotaCacheTTL
+	}
+	return &quotaCache{ttl: ttl, entries: make(map[quotaCacheKey]quotaCacheEntry)}
+}
+
+func (c *quotaCache) get(key quotaCacheKey) (float64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return 0, false
+	}
+	return entry.value, true
+}
+
+func (c *quotaCache) set(key quotaCacheKey, value float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = quotaCacheEntry{value: value, expiresAt: time.Now().Add(c.ttl)}
+}
+
+type instanceTypeVCPUCacheKey struct {
+	region       string
+	instanceType string
+}
+
+type instanceTypeVCPUCacheEntry struct {
+	vcpus     int32
+	expiresAt time.Time
+}
+
+// instanceTypeVCPUCache memoizes DescribeInstanceTypes lookups per (region,
+// instanceType) for ttl, so usedVCPUs doesn't re-describe every running
+// instance's type on every checkCapacity call.
+type instanceTypeVCPUCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[instanceTypeVCPUCacheKey]instanceTypeVCPUCacheEntry
+}
+
+func newInstanceTypeVCPUCache(ttl time.Duration) *instanceTypeVCPUCache {
+	if ttl <= 0 {
+		ttl = defaultQuotaCacheTTL
+	}
+	return &instanceTypeVCPUCache{ttl: ttl, entries: make(map[instanceTypeVCPUCacheKey]instanceTypeVCPUCacheEntry)}
+}
+
+func (c *instanceTypeVCPUCache) get(key instanceTypeVCPUCacheKey) (int32, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return 0, false
+	}
+	return entry.vcpus, true
+}
+
+func (c *instanceTypeVCPUCache) set(key instanceTypeVCPUCacheKey, vcpus int32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = instanceTypeVCPUCacheEntry{vcpus: vcpus, expiresAt: time.Now().Add(c.ttl)}
+}
+
+// checkCapacity returns ErrRegionQuotaExhausted if launching instanceType in
+// region would exceed the account's running-on-demand-standard-instances
+// vCPU quota, so the orchestrator can delay the job or pick another region
+// instead of hitting VcpuLimitExceeded on RunInstances.
+func (c *Client) checkCapacity(ctx context.Context, region, instanceType string) error {
+	vcpus, err := c.instanceTypeVCPUs(ctx, region, instanceType)
+	if err != nil {
+		return fmt.Errorf("failed to get vCPU count for instance type %s: %w", instanceType, err)
+	}
+
+	quota, err := c.getQuota(ctx, region, standardInstancesQuotaCode)
+	if err != nil {
+		return fmt.Errorf("failed to get EC2 service quota for region %s: %w", region, err)
+	}
+
+	used, err := c.usedVCPUs(ctx, region)
+	if err != nil {
+		return fmt.Errorf("failed to get current vCPU usage for region %s: %w", region, err)
+	}
+
+	available := quota - used
+	if available < float64(vcpus) {
+		return &ErrRegionQuotaExhausted{Region: region, Requested: float64(vcpus), Available: available}
+	}
+
+	return nil
+}
+
+func (c *Client) getQuota(ctx context.Context, region, quotaCode string) (float64, error) {
+	key := quotaCacheKey{region: region, quotaCode: quotaCode}
+	if value, ok := c.quotaCache.get(key); ok {
+		return value, nil
+	}
+
+	out, err := c.quotaClient.GetServiceQuota(ctx, &servicequotas.GetServiceQuotaInput{
+		ServiceCode: utils.StringPtr(ec2QuotaServiceCode),
+		QuotaCode:   &quotaCode,
+	}, func(options *servicequotas.Options) {
+		options.Region = region
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get service quota %s: %w", quotaCode, err)
+	}
+	if out.Quota == nil || out.Quota.Value == nil {
+		return 0, fmt.Errorf("service quota %s returned no value", quotaCode)
+	}
+
+	c.quotaCache.set(key, *out.Quota.Value)
+
+	return *out.Quota.Value, nil
+}
+
+// usedVCPUs sums the vCPUs of every running or pending instance in region.
+func (c *Client) usedVCPUs(ctx context.Context, region string) (float64, error) {
+	filters := []ec2types.Filter{
+		{
+			Name:   utils.StringPtr(instanceStateFilterName),
+			Values: []string{"running", "pending"},
+		},
+	}
+
+	out, err := c.ec2Client.DescribeInstances(ctx, &ec2.DescribeInstancesInput{
+		Filters: filters,
+	}, func(options *ec2.Options) {
+		options.Region = region
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to describe instances: %w", err)
+	}
+
+	total, err := c.sumReservationsVCPUs(ctx, region, out.Reservations)
+	if err != nil {
+		return 0, err
+	}
+
+	// use pagination
+	for out.NextToken != nil {
+		out, err = c.ec2Client.DescribeInstances(ctx, &ec2.DescribeInstancesInput{
+			Filters:   filters,
+			NextToken: out.NextToken,
+		}, func(options *ec2.Options) {
+			options.Region = region
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to describe instances: %w", err)
+		}
+
+		pageTotal, err := c.sumReservationsVCPUs(ctx, region, out.Reservations)
+		if err != nil {
+			return 0, err
+		}
+		total += pageTotal
+	}
+
+	return total, nil
+}
+
+// sumReservationsVCPUs adds up the vCPUs of every instance across
+// reservations.
+func (c *Client) sumReservationsVCPUs(ctx context.Context, region string, reservations []ec2types.Reservation) (float64, error) {
+	var total float64
+	for _, reservation := range reservations {
+		for _, instance := range reservation.Instances {
+			vcpus, err := c.instanceTypeVCPUs(ctx, region, string(instance.InstanceType))
+			if err != nil {
+				return 0, err
+			}
+			total += float64(vcpus)
+		}
+	}
+
+	return total, nil
+}
+
+// instanceTypeVCPUs looks up how many vCPUs a given instance type has.
+func (c *Client) instanceTypeVCPUs(ctx context.Context, region, instanceType string) (int32, error) {
+	key := instanceTypeVCPUCacheKey{region: region, instanceType: instanceType}
+	if vcpus, ok := c.instanceTypeVCPUCache.get(key); ok {
+		return vcpus, nil
+	}
+
+	out, err := c.ec2Client.DescribeInstanceTypes(ctx, &ec2.DescribeInstanceTypesInput{
+		InstanceTypes: []ec2types.InstanceType{ec2types.InstanceType(instanceType)},
+	}, func(options *ec2.Options) {
+		options.Region = region
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to describe instance type %s: %w", instanceType, err)
+	}
+	if len(out.InstanceTypes) == 0 || out.InstanceTypes[0].VCpuInfo == nil || out.InstanceTypes[0].VCpuInfo.DefaultVCpus == nil {
+		return 0, fmt.Errorf("no vCPU info returned for instance type %s", instanceType)
+	}
+
+	vcpus := *out.InstanceTypes[0].VCpuInfo.DefaultVCpus
+	c.instanceTypeVCPUCache.set(key, vcpus)
+
+	return vcpus, nil
+}
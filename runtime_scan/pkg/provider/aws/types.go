@@ -0,0 +1,53 @@
+// Copyright © 2022 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aws
+
+// ScanScope is the internal representation of models.AwsScanScope, resolved
+// into the shape the ec2 calls in this package need.
+type ScanScope struct {
+	AllRegions  bool
+	Regions     []Region
+	ScanStopped bool
+	TagSelector []Tag
+	ExcludeTags []Tag
+
+	// ExcludeRegions holds region name patterns (e.g. "us-gov-*") to
+	// leave out of the scan regardless of AllRegions/Regions.
+	ExcludeRegions []string
+	// ExcludeVPCs holds VPC IDs to leave out of the scan.
+	ExcludeVPCs []string
+	// ExcludeInstanceIDs holds instance IDs to leave out of the scan.
+	ExcludeInstanceIDs []string
+}
+
+type Region struct {
+	name string
+	vpcs []VPC
+}
+
+type VPC struct {
+	id             string
+	securityGroups []SecurityGroup
+}
+
+type SecurityGroup struct {
+	id string
+}
+
+type Tag struct {
+	Key string
+	Val string
+}
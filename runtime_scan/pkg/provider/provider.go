@@ -0,0 +1,57 @@
+// Copyright © 2022 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provider
+
+import (
+	"context"
+
+	"github.com/openclarity/vmclarity/api/models"
+	"github.com/openclarity/vmclarity/runtime_scan/pkg/types"
+)
+
+// ScanningJobConfig carries everything a provider needs to launch a scanner
+// job for a single target instance.
+type ScanningJobConfig struct {
+	ScannerCLIConfig string
+	ScannerImage     string
+	VMClarityAddress string
+	ScanResultID     string
+	KeyPairName      string
+
+	// UseSpot overrides the provider-level default (if set) for this job,
+	// requesting spot capacity with fallback to on-demand.
+	UseSpot *bool
+	// MaxSpotPrice overrides the provider-level default for this job.
+	MaxSpotPrice string
+	// SpotInterruptionBehavior overrides the provider-level default for
+	// this job: "terminate", "stop", or "hibernate".
+	SpotInterruptionBehavior string
+
+	// OnSpotInterrupted, if set, is called when the provider detects that
+	// a spot-launched instance for this job has been marked for
+	// reclamation, so the caller can mark ScanResultID as needing retry
+	// before AWS's ~2 minute warning expires. It's a no-op for on-demand
+	// instances.
+	OnSpotInterrupted func()
+}
+
+// Provider is implemented by each cloud-specific provider (aws, azure, ...).
+type Provider interface {
+	// Discover returns the instances matching the given scan scope.
+	Discover(ctx context.Context, scanScope *models.ScanScopeType) ([]types.Instance, error)
+	// RunScanningJob launches a scanner job targeting the given instance.
+	RunScanningJob(ctx context.Context, region, id string, config ScanningJobConfig) (types.Instance, error)
+}
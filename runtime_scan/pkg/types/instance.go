@@ -0,0 +1,43 @@
+// Copyright © 2022 Cisco Systems, Inc. and its affiliates.
+// All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import "context"
+
+// Instance is the provider-agnostic handle returned by discovery and
+// scanner-job creation. Provider implementations wrap their native
+// resources (e.g. an EC2 instance) behind this interface.
+type Instance interface {
+	// GetID returns the provider-specific identifier of the instance.
+	GetID() string
+	// GetRegion returns the region the instance lives in.
+	GetRegion() string
+	// WaitForReady blocks until the instance is ready to be scanned.
+	WaitForReady(ctx context.Context) error
+	// Delete removes the instance.
+	Delete(ctx context.Context) error
+	// GetVolumes returns the volumes attached to the instance, so callers
+	// (e.g. the scan-estimation cost model) can size snapshots without
+	// needing provider-specific knowledge.
+	GetVolumes(ctx context.Context) ([]Volume, error)
+}
+
+// Volume describes a storage volume attached to an Instance.
+type Volume struct {
+	ID         string
+	SizeGB     int32
+	VolumeType string
+}